@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
+)
+
+func createMerchant(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("merchant create", flag.ExitOnError)
+	owner := flags.String("owner", "", "username of the admin owning the merchant")
+	payload := &merchant_entity.AddMerchantRequest{}
+	flags.StringVar(&payload.Name, "name", "", "merchant name")
+	flags.StringVar(&payload.Category, "category", "", "merchant category")
+	flags.StringVar(&payload.ImageURL, "image-url", "", "merchant image URL")
+	location := &merchant_entity.Location{}
+	flags.Float64Var(&location.Lat, "lat", 0, "latitude")
+	flags.Float64Var(&location.Long, "long", 0, "longitude")
+	flags.StringVar(&payload.Address, "address", "", "street address, geocoded when --lat and --long are omitted")
+	prepTime := flags.Int("prep-time", 0, "average preparation time in minutes, the default when omitted")
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+	if location.Lat != 0 || location.Long != 0 || payload.Address == "" {
+		payload.Location = location
+	}
+	if *prepTime != 0 {
+		payload.PreparationMinutes = prepTime
+	}
+
+	if err := validator_helper.ValidatePayload(payload, "en"); err != nil {
+		return err
+	}
+
+	if *owner == "" {
+		return errors.New("--owner is required")
+	}
+	admin, err := a.users.GetUserByUsername(ctx, *owner)
+	if err != nil {
+		return err
+	}
+	if !admin.IsAdmin {
+		return errors.New("--owner must be the username of an admin")
+	}
+
+	merchant, err := a.merchant.CreateMerchant(ctx, admin.Id, payload)
+	if err != nil {
+		return err
+	}
+	return a.out.print(merchant, []string{"MERCHANT ID"}, [][]string{{merchant.Id}})
+}
+
+func setPreparationTime(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("merchant prep-time", flag.ExitOnError)
+	values, err := parseArgs(flags, args, "ID", "MINUTES")
+	if err != nil {
+		return err
+	}
+
+	var minutes *int
+	if values[1] != "default" {
+		n, err := strconv.Atoi(values[1])
+		if err != nil || n < 1 || n > 240 {
+			return errors.New("MINUTES must be a whole number from 1 to 240 or default")
+		}
+		minutes = &n
+	}
+
+	if err := a.merchant.SetPreparationTime(ctx, values[0], minutes); err != nil {
+		return err
+	}
+	return a.out.print(map[string]any{"merchantId": values[0], "preparationTimeInMinutes": minutes},
+		[]string{"MERCHANT ID", "PREPARATION TIME (MIN)"},
+		[][]string{{values[0], values[1]}},
+	)
+}
+
+func createItem(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("item create", flag.ExitOnError)
+	merchantId := flags.String("merchant", "", "id of the merchant selling the item")
+	payload := &item_entity.AddItemRequest{}
+	flags.StringVar(&payload.Name, "name", "", "item name")
+	flags.StringVar(&payload.Category, "category", "", "product category")
+	flags.IntVar(&payload.Price, "price", 0, "price")
+	flags.StringVar(&payload.ImageURL, "image-url", "", "item image URL")
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if err := validator_helper.ValidatePayload(payload, "en"); err != nil {
+		return err
+	}
+
+	item, err := a.item.CreateItem(ctx, *merchantId, payload)
+	if err != nil {
+		return err
+	}
+	return a.out.print(item, []string{"ITEM ID"}, [][]string{{item.Id}})
+}
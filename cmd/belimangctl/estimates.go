@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strconv"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+func showEstimate(ctx context.Context, a *app, args []string) error {
+	return runEstimate(ctx, a, args, "estimate show", a.purchase.GetEstimate)
+}
+
+func repriceEstimate(ctx context.Context, a *app, args []string) error {
+	return runEstimate(ctx, a, args, "estimate reprice", a.purchase.RepriceEstimate)
+}
+
+func voidEstimate(ctx context.Context, a *app, args []string) error {
+	return runEstimate(ctx, a, args, "estimate void", a.purchase.VoidEstimate)
+}
+
+func runEstimate(ctx context.Context, a *app, args []string, name string, fn func(context.Context, string) (*purchase_entity.GetEstimate, error)) error {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	values, err := parseArgs(flags, args, "ID")
+	if err != nil {
+		return err
+	}
+
+	estimate, err := fn(ctx, values[0])
+	if err != nil {
+		return err
+	}
+
+	previousTotalPrice := "-"
+	if estimate.PreviousTotalPrice != nil {
+		previousTotalPrice = strconv.Itoa(*estimate.PreviousTotalPrice)
+	}
+	return a.out.print(
+		estimate,
+		[]string{"ID", "STATUS", "TOTAL PRICE", "PREVIOUS TOTAL PRICE", "DELIVERY TIME (MIN)", "CREATED AT"},
+		[][]string{{
+			estimate.Id,
+			estimate.Status,
+			strconv.Itoa(estimate.TotalPrice),
+			previousTotalPrice,
+			strconv.Itoa(estimate.DeliveryTime),
+			estimate.CreatedAt,
+		}},
+	)
+}
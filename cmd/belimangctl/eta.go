@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strconv"
+)
+
+func listSpeedProfiles(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("eta profiles", flag.ExitOnError)
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	profiles, err := a.eta.GetSpeedProfiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	days := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', 2, 64) }
+	rows := make([][]string, 0, len(profiles))
+	for _, p := range profiles {
+		rows = append(rows, []string{
+			days[p.HourOfWeek/24] + " " + strconv.Itoa(p.HourOfWeek%24) + ":00",
+			format(p.Factor),
+			format(p.FactorLow),
+			format(p.FactorHigh),
+			strconv.Itoa(p.Samples),
+			p.UpdatedAt,
+		})
+	}
+	return a.out.print(profiles, []string{"HOUR", "FACTOR", "LOW", "HIGH", "SAMPLES", "UPDATED AT"}, rows)
+}
+
+func calibrateSpeedProfiles(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("eta calibrate", flag.ExitOnError)
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	report, err := a.eta.Calibrate(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.print(report,
+		[]string{"DELIVERIES", "USED", "CALIBRATED HOURS"},
+		[][]string{{strconv.Itoa(report.Deliveries), strconv.Itoa(report.Used), strconv.Itoa(report.Hours)}},
+	)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+
+	geocoding_entity "github.com/danzBraham/beli-mang/internal/entities/geocoding"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
+)
+
+func importAddresses(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("geocoder import", flag.ExitOnError)
+	replace := flags.Bool("replace", false, "remove the addresses imported before")
+	values, err := parseArgs(flags, args, "FILE")
+	if err != nil {
+		return err
+	}
+
+	// FILE is an OpenAddresses CSV, - reads it from stdin
+	var r io.Reader = a.stdin
+	if values[0] != "-" {
+		file, err := os.Open(values[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	report, err := geocoding.ImportOpenAddresses(ctx, a.addresses, r, *replace)
+	if err != nil {
+		return err
+	}
+	return a.out.print(report,
+		[]string{"IMPORTED", "SKIPPED", "REPLACED"},
+		[][]string{{strconv.FormatInt(report.Imported, 10), strconv.Itoa(report.Skipped), strconv.FormatBool(report.Replaced)}},
+	)
+}
+
+func searchAddress(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("geocoder search", flag.ExitOnError)
+	values, err := parseArgs(flags, args, "ADDRESS")
+	if err != nil {
+		return err
+	}
+
+	place, err := a.geocoder.Geocode(ctx, values[0])
+	if err != nil {
+		return geocoderError(err)
+	}
+	return printPlace(a, place)
+}
+
+func reverseGeocode(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("geocoder reverse", flag.ExitOnError)
+	values, err := parseArgs(flags, args, "LAT", "LONG")
+	if err != nil {
+		return err
+	}
+
+	lat, err := strconv.ParseFloat(values[0], 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return errors.New("LAT must be a latitude in degrees")
+	}
+	long, err := strconv.ParseFloat(values[1], 64)
+	if err != nil || long < -180 || long > 180 {
+		return errors.New("LONG must be a longitude in degrees")
+	}
+
+	place, err := a.geocoder.Reverse(ctx, purchase_entity.Location{Lat: lat, Long: long})
+	if err != nil {
+		return geocoderError(err)
+	}
+	return printPlace(a, place)
+}
+
+func printPlace(a *app, place *geocoding_entity.Place) error {
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	return a.out.print(place,
+		[]string{"ADDRESS", "LAT", "LONG"},
+		[][]string{{place.Address, format(place.Location.Lat), format(place.Location.Long)}},
+	)
+}
+
+func geocoderError(err error) error {
+	switch {
+	case errors.Is(err, geocoding.ErrNotFound):
+		return errors.New("no address found")
+	case errors.Is(err, geocoding.ErrDisabled):
+		return errors.New("the geocoder is disabled, set GEOCODER_PROVIDER")
+	}
+	return err
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+	"github.com/danzBraham/beli-mang/internal/db"
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
+	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/routing"
+	"github.com/danzBraham/beli-mang/internal/services"
+)
+
+const usage = `Usage: belimangctl [-o table|json] [-config FILE] <command> <subcommand> [arguments]
+
+Commands:
+  user create-admin --username U --email E (--password P | --password-stdin)
+  user promote USERNAME
+  user reset-password USERNAME (--password P | --password-stdin)
+  user list [--role admin|user] [--username U] [--limit N] [--offset N]
+  user disable USERNAME
+  user enable USERNAME
+  merchant create --owner ADMIN --name N --category C --image-url URL (--lat LAT --long LONG | --address A) [--prep-time MIN]
+  merchant prep-time ID (MINUTES | default)
+  item create --merchant ID --name N --category C --price P --image-url URL
+  estimate show ID
+  estimate reprice ID
+  estimate void ID
+  order deliver ID [--at TIME]
+  eta profiles
+  eta calibrate
+  geocoder import FILE [--replace]
+  geocoder search ADDRESS
+  geocoder reverse LAT LONG
+`
+
+type command func(ctx context.Context, app *app, args []string) error
+
+var commands = map[string]map[string]command{
+	"user": {
+		"create-admin":   createAdmin,
+		"promote":        promoteUser,
+		"reset-password": resetPassword,
+		"list":           listUsers,
+		"disable":        disableUser,
+		"enable":         enableUser,
+	},
+	"merchant": {
+		"create":    createMerchant,
+		"prep-time": setPreparationTime,
+	},
+	"item": {
+		"create": createItem,
+	},
+	"estimate": {
+		"show":    showEstimate,
+		"reprice": repriceEstimate,
+		"void":    voidEstimate,
+	},
+	"order": {
+		"deliver": deliverOrder,
+	},
+	"eta": {
+		"profiles":  listSpeedProfiles,
+		"calibrate": calibrateSpeedProfiles,
+	},
+	"geocoder": {
+		"import":  importAddresses,
+		"search":  searchAddress,
+		"reverse": reverseGeocode,
+	},
+}
+
+type app struct {
+	out      *printer
+	stdin    io.Reader
+	users    services.UserService
+	merchant services.MerchantService
+	item     services.ItemService
+	purchase services.PurchaseService
+	eta      services.EtaService
+	geocoder geocoding.Geocoder
+	// addresses is the dataset the postgis geocoder searches
+	addresses repositories.GeocoderRepository
+}
+
+func main() {
+	flags := flag.NewFlagSet("belimangctl", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	output := flags.String("o", "table", "output format, table or json")
+	configFile := flags.String("config", "", "YAML config file, defaults to CONFIG_FILE")
+	flags.Parse(os.Args[1:])
+
+	args := flags.Args()
+	if len(args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[args[0]][args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0]+" "+args[1], usage)
+		os.Exit(2)
+	}
+
+	out, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		exit(err)
+	}
+
+	cfg, err := config.Read(*configFile)
+	if err == nil {
+		err = cfg.ValidateCtl()
+	}
+	if err != nil {
+		exit(err)
+	}
+
+	// keep the service logs out of the command output
+	cfg.Log.Level = "warn"
+	l, err := logger.New(os.Stderr, cfg.Log)
+	if err != nil {
+		exit(err)
+	}
+	slog.SetDefault(l)
+
+	validator_helper.InitCustomValidation()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.Connect(cfg.DB)
+	if err != nil {
+		exit(fmt.Errorf("connect to the database: %w", err))
+	}
+	defer pool.Close()
+
+	userRepository := repositories.NewUserRepository(pool)
+	merchantRepository := repositories.NewMerchantRepository(pool)
+	itemRepository := repositories.NewItemRepository(pool)
+	purchaseRepository := repositories.NewPurchaseRepository(pool)
+	etaService := services.NewEtaService(repositories.NewEtaRepository(pool), cfg.ETA)
+	geocoderRepository := repositories.NewGeocoderRepository(pool)
+	geocoder, err := geocoding.New(cfg.Geocoder, geocoderRepository)
+	if err != nil {
+		exit(err)
+	}
+
+	straightLine := routing.NewHaversineRouter(cfg.Routing.FallbackSpeedKmh)
+	a := &app{
+		out:      out,
+		stdin:    os.Stdin,
+		users:    services.NewUserService(userRepository, cfg.Auth),
+		merchant: services.NewMerchantService(merchantRepository, geocoder),
+		item:     services.NewItemService(itemRepository, merchantRepository),
+		// estimates are never calculated here, so the road network isn't loaded
+		purchase:  services.NewPurchaseService(purchaseRepository, merchantRepository, itemRepository, repositories.NewAddressRepository(pool), straightLine, straightLine, geocoder, etaService, cfg.Purchase),
+		eta:       etaService,
+		geocoder:  geocoder,
+		addresses: geocoderRepository,
+	}
+
+	if err := cmd(ctx, a, args[2:]); err != nil {
+		pool.Close()
+		exit(err)
+	}
+}
+
+// exit prints err in a form meant for operators and terminates. Validation
+// failures list every invalid field.
+func exit(err error) {
+	appErr := exceptions.Translate(err)
+	switch {
+	case len(appErr.Errors) > 0:
+		fmt.Fprintln(os.Stderr, "error:", appErr.Message)
+		for _, fieldErr := range appErr.Errors {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", fieldErr.Field, fieldErr.Message)
+		}
+	case errors.Is(appErr, exceptions.ErrInternal):
+		fmt.Fprintln(os.Stderr, "error:", err)
+	default:
+		fmt.Fprintln(os.Stderr, "error:", appErr.Message)
+	}
+	os.Exit(1)
+}
+
+// parseArgs parses flags that may appear before or after the positional
+// arguments and returns exactly the expected number of positionals.
+func parseArgs(flags *flag.FlagSet, args []string, positionals ...string) ([]string, error) {
+	var values []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		values = append(values, args[0])
+		args = args[1:]
+	}
+
+	if len(values) != len(positionals) {
+		if len(positionals) == 0 {
+			return nil, fmt.Errorf("%s takes no arguments", flags.Name())
+		}
+		return nil, fmt.Errorf("%s expects %v", flags.Name(), positionals)
+	}
+	return values, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+)
+
+func deliverOrder(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("order deliver", flag.ExitOnError)
+	at := flags.String("at", "", "delivery time in RFC 3339, defaults to now")
+	values, err := parseArgs(flags, args, "ID")
+	if err != nil {
+		return err
+	}
+
+	deliveredAt := time.Now()
+	if *at != "" {
+		if deliveredAt, err = time.Parse(time.RFC3339, *at); err != nil {
+			return errors.New("--at must be an RFC 3339 time such as 2024-05-01T12:30:00+07:00")
+		}
+	}
+
+	delivered, err := a.purchase.DeliverOrder(ctx, values[0], "", deliveredAt)
+	if err != nil {
+		return err
+	}
+	return a.out.print(delivered,
+		[]string{"ORDER ID", "DELIVERED AT"},
+		[][]string{{delivered.OrderId, delivered.DeliveredAt}},
+	)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+
+	user_entity "github.com/danzBraham/beli-mang/internal/entities/user"
+	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
+)
+
+var userHeaders = []string{"ID", "USERNAME", "EMAIL", "ADMIN", "DISABLED", "CREATED AT"}
+
+func userRow(user *user_entity.GetUser) []string {
+	return []string{
+		user.Id,
+		user.Username,
+		user.Email,
+		strconv.FormatBool(user.IsAdmin),
+		strconv.FormatBool(user.IsDisabled),
+		user.CreatedAt,
+	}
+}
+
+func createAdmin(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user create-admin", flag.ExitOnError)
+	username := flags.String("username", "", "admin username")
+	email := flags.String("email", "", "admin email")
+	password, passwordStdin := passwordFlags(flags)
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	payload := &user_entity.RegisterUserRequest{Username: *username, Email: *email}
+	var err error
+	payload.Password, err = readPassword(a, *password, *passwordStdin)
+	if err != nil {
+		return err
+	}
+	if err := validator_helper.ValidatePayload(payload, "en"); err != nil {
+		return err
+	}
+
+	if _, err := a.users.RegisterAdminUser(ctx, payload); err != nil {
+		return err
+	}
+
+	user, err := a.users.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		return err
+	}
+	return printUsers(a, []*user_entity.GetUser{user})
+}
+
+func promoteUser(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user promote", flag.ExitOnError)
+	values, err := parseArgs(flags, args, "USERNAME")
+	if err != nil {
+		return err
+	}
+
+	user, err := a.users.PromoteUser(ctx, values[0])
+	if err != nil {
+		return err
+	}
+	return printUsers(a, []*user_entity.GetUser{user})
+}
+
+func resetPassword(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user reset-password", flag.ExitOnError)
+	password, passwordStdin := passwordFlags(flags)
+	values, err := parseArgs(flags, args, "USERNAME")
+	if err != nil {
+		return err
+	}
+
+	payload := &user_entity.ResetPasswordRequest{}
+	payload.Password, err = readPassword(a, *password, *passwordStdin)
+	if err != nil {
+		return err
+	}
+	if err := validator_helper.ValidatePayload(payload, "en"); err != nil {
+		return err
+	}
+
+	if err := a.users.ResetPassword(ctx, values[0], payload); err != nil {
+		return err
+	}
+	return a.out.print(
+		map[string]string{"username": values[0], "status": "password reset"},
+		[]string{"USERNAME", "STATUS"},
+		[][]string{{values[0], "password reset"}},
+	)
+}
+
+func listUsers(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user list", flag.ExitOnError)
+	params := &user_entity.UserQueryParams{}
+	flags.StringVar(&params.Role, "role", "", "only list admin or user accounts")
+	flags.StringVar(&params.Username, "username", "", "filter by username, case insensitive")
+	flags.IntVar(&params.Limit, "limit", 20, "maximum number of users")
+	flags.IntVar(&params.Offset, "offset", 0, "number of users to skip")
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if params.Role != "" && params.Role != "admin" && params.Role != "user" {
+		return errors.New("--role must be admin or user")
+	}
+	if params.Limit <= 0 || params.Offset < 0 {
+		return errors.New("--limit must be positive and --offset must not be negative")
+	}
+
+	users, err := a.users.GetUsers(ctx, params)
+	if err != nil {
+		return err
+	}
+	return printUsers(a, users)
+}
+
+func disableUser(ctx context.Context, a *app, args []string) error {
+	return setUserDisabled(ctx, a, args, "user disable", true)
+}
+
+func enableUser(ctx context.Context, a *app, args []string) error {
+	return setUserDisabled(ctx, a, args, "user enable", false)
+}
+
+func setUserDisabled(ctx context.Context, a *app, args []string, name string, isDisabled bool) error {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	values, err := parseArgs(flags, args, "USERNAME")
+	if err != nil {
+		return err
+	}
+
+	user, err := a.users.SetUserDisabled(ctx, values[0], isDisabled)
+	if err != nil {
+		return err
+	}
+	return printUsers(a, []*user_entity.GetUser{user})
+}
+
+func printUsers(a *app, users []*user_entity.GetUser) error {
+	rows := make([][]string, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, userRow(user))
+	}
+	return a.out.print(users, userHeaders, rows)
+}
+
+func passwordFlags(flags *flag.FlagSet) (password *string, passwordStdin *bool) {
+	password = flags.String("password", "", "new password, prefer --password-stdin to keep it out of the shell history")
+	passwordStdin = flags.Bool("password-stdin", false, "read the password from the first line of stdin")
+	return password, passwordStdin
+}
+
+func readPassword(a *app, password string, fromStdin bool) (string, error) {
+	if !fromStdin {
+		return password, nil
+	}
+	if password != "" {
+		return "", errors.New("use either --password or --password-stdin")
+	}
+
+	line, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password on stdin")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
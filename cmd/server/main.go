@@ -1,28 +1,74 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
 
-	"github.com/danzBraham/beli-mang/internal/db"
-	"github.com/danzBraham/beli-mang/internal/http"
-	"github.com/joho/godotenv"
+	"github.com/danzBraham/beli-mang/internal/config"
+	"github.com/danzBraham/beli-mang/internal/logger"
 )
 
+const usage = `Usage: beli-mang <command> [arguments]
+
+Commands:
+  serve [--auto-migrate]        start the API server (default)
+  migrate up                    apply all pending migrations
+  migrate down [N]              roll back the last N migrations (default 1)
+  migrate status                list migrations and whether they are applied
+  migrate create [--dir D] NAME create a new pair of migration files
+  seed [flags]                  insert reproducible fake data, see seed --help
+`
+
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file")
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = serve(args)
+	case "migrate":
+		err = migrate(args)
+	case "seed":
+		err = seed(args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
 	}
 
-	pool, err := db.Connect()
 	if err != nil {
-		log.Fatalf("Failed to connect to the database: %v", err)
+		fatal(command+" failed", err)
 	}
-	defer pool.Close()
+}
 
-	addr := os.Getenv("APP_HOST") + ":" + os.Getenv("APP_PORT")
-	server := http.NewAPIServer(addr, pool)
-	if err := server.Launch(); err != nil {
+// setup loads the configuration, checks it with validate and installs the
+// default logger.
+func setup(validate func(*config.Config) error) *config.Config {
+	cfg, err := config.Read("")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := validate(cfg); err != nil {
 		log.Fatal(err)
 	}
+
+	l, err := logger.New(os.Stdout, cfg.Log)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(l)
+
+	return cfg
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+	"github.com/danzBraham/beli-mang/internal/db"
+)
+
+var migrationNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing migrate subcommand, expected up, down, status or create")
+	}
+	subcommand, args := args[0], args[1:]
+
+	if subcommand == "create" {
+		return createMigration(args)
+	}
+
+	cfg := setup((*config.Config).ValidateDB)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.Connect(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("connect to the database: %w", err)
+	}
+	defer pool.Close()
+
+	migrator, err := db.NewMigrator(pool)
+	if err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			steps, err = strconv.Atoi(args[0])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of steps %q", args[0])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migration(s)\n", reverted)
+	case "status":
+		version, dirty, statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("current version: %d", version)
+		if dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, s := range statuses {
+			status := "pending"
+			if s.Applied {
+				status = "applied"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, status)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q", subcommand)
+	}
+
+	return nil
+}
+
+func createMigration(args []string) error {
+	flags := flag.NewFlagSet("migrate create", flag.ExitOnError)
+	dir := flags.String("dir", filepath.Join("db", "migrations"), "directory to write the migration files to")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("migrate create expects exactly one NAME")
+	}
+	name := strings.ToLower(flags.Arg(0))
+	if !migrationNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid migration name %q, use lowercase letters, digits and underscores", name)
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(*dir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+	"github.com/danzBraham/beli-mang/internal/db"
+	bcrypt_helper "github.com/danzBraham/beli-mang/internal/helpers/bcrypt"
+	"github.com/danzBraham/beli-mang/internal/seeder"
+)
+
+func seed(args []string) error {
+	opts := seeder.DefaultOptions()
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	flags.Uint64Var(&opts.Seed, "seed", opts.Seed, "random seed, the same seed always produces the same data")
+	flags.IntVar(&opts.Admins, "admins", opts.Admins, "number of admins owning the merchants")
+	flags.IntVar(&opts.Users, "users", opts.Users, "number of users")
+	flags.IntVar(&opts.Merchants, "merchants", opts.Merchants, "number of merchants")
+	flags.IntVar(&opts.ItemsPerCategory, "items-per-category", opts.ItemsPerCategory, "items per product category and merchant")
+	flags.IntVar(&opts.Orders, "orders", opts.Orders, "number of historical orders")
+	flags.Float64Var(&opts.Center.Lat, "center-lat", opts.Center.Lat, "latitude of the area center")
+	flags.Float64Var(&opts.Center.Long, "center-long", opts.Center.Long, "longitude of the area center")
+	flags.Float64Var(&opts.RadiusKm, "radius-km", opts.RadiusKm, "radius of the area in kilometers")
+	flags.IntVar(&opts.Clusters, "clusters", opts.Clusters, "number of districts merchants gather around")
+	until := flags.String("until", opts.Until.Format(time.RFC3339), "newest creation time, data spans the 90 days before")
+	password := flags.String("password", "password", "password shared by every seeded user")
+	reset := flags.Bool("reset", false, "delete all existing data before seeding")
+	flags.Parse(args)
+
+	var err error
+	opts.Until, err = time.Parse(time.RFC3339, *until)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	cfg := setup((*config.Config).ValidateDB)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	data, err := seeder.Generate(opts)
+	if err != nil {
+		return err
+	}
+	slog.Info("seed data generated",
+		"seed", opts.Seed,
+		"users", len(data.Users),
+		"merchants", len(data.Merchants),
+		"items", len(data.Items),
+		"orders", len(data.Orders),
+		"duration", time.Since(start),
+	)
+
+	// one hash for everyone, hashing thousands of passwords would dominate
+	passwordHash, err := bcrypt_helper.HashPassword(*password, cfg.Auth.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	pool, err := db.Connect(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("connect to the database: %w", err)
+	}
+	defer pool.Close()
+
+	start = time.Now()
+	if err := seeder.Insert(ctx, pool, data, passwordHash, *reset); err != nil {
+		return err
+	}
+	slog.Info("seed data inserted", "duration", time.Since(start))
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+	"github.com/danzBraham/beli-mang/internal/db"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
+	"github.com/danzBraham/beli-mang/internal/http"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/routing"
+	"github.com/danzBraham/beli-mang/internal/tracing"
+)
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	autoMigrate := flags.Bool("auto-migrate", false, "apply pending migrations before serving (overrides DB_AUTO_MIGRATE)")
+	flags.Parse(args)
+
+	cfg := setup((*config.Config).Validate)
+	if *autoMigrate {
+		cfg.DB.AutoMigrate = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("set up tracing: %w", err)
+	}
+
+	pool, err := db.Connect(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("connect to the database: %w", err)
+	}
+
+	if cfg.DB.AutoMigrate {
+		migrator, err := db.NewMigrator(pool)
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("auto-migrate: %w", err)
+		}
+		slog.Info("database migrated", "applied", applied)
+	}
+
+	router, err := routing.New(cfg.Routing)
+	if err != nil {
+		return err
+	}
+
+	geocoder, err := geocoding.New(cfg.Geocoder, repositories.NewGeocoderRepository(pool))
+	if err != nil {
+		return err
+	}
+
+	server := http.NewAPIServer(cfg, pool, router, geocoder)
+	err = server.Launch(ctx)
+
+	pool.Close()
+	slog.Info("database pool closed")
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("server stopped unexpectedly: %w", err)
+	}
+	return nil
+}
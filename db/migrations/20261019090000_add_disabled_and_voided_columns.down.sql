@@ -0,0 +1,2 @@
+ALTER TABLE estimates DROP COLUMN IF EXISTS voided_at;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
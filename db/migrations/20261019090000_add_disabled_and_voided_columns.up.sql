@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP;
+ALTER TABLE estimates ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_items_merchant_id_created_at;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_items_merchant_id_created_at ON items (merchant_id, created_at DESC, id);
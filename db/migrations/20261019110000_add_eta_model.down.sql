@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS speed_profiles;
+
+DROP INDEX IF EXISTS idx_orders_delivered_at;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS delivered_at;
+
+ALTER TABLE estimates
+  DROP COLUMN IF EXISTS route_distance_km,
+  DROP COLUMN IF EXISTS route_duration_seconds,
+  DROP COLUMN IF EXISTS preparation_minutes;
+
+ALTER TABLE merchants DROP COLUMN IF EXISTS preparation_minutes;
@@ -0,0 +1,19 @@
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS preparation_minutes INT;
+
+ALTER TABLE estimates
+  ADD COLUMN IF NOT EXISTS route_distance_km DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS route_duration_seconds INT,
+  ADD COLUMN IF NOT EXISTS preparation_minutes INT;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_orders_delivered_at ON orders (delivered_at) WHERE delivered_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS speed_profiles (
+  hour_of_week SMALLINT PRIMARY KEY NOT NULL CHECK (hour_of_week BETWEEN 0 AND 167),
+  factor DOUBLE PRECISION NOT NULL,
+  factor_low DOUBLE PRECISION NOT NULL,
+  factor_high DOUBLE PRECISION NOT NULL,
+  samples INT NOT NULL,
+  updated_at TIMESTAMP DEFAULT NOW()
+);
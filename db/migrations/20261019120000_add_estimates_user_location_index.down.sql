@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_estimates_user_location;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_estimates_user_location ON estimates USING GIST (user_location);
@@ -0,0 +1,5 @@
+ALTER TABLE estimates DROP COLUMN IF EXISTS user_address;
+
+ALTER TABLE merchants DROP COLUMN IF EXISTS address;
+
+DROP TABLE IF EXISTS geocoder_addresses;
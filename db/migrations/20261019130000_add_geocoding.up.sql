@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS geocoder_addresses (
+  id BIGSERIAL PRIMARY KEY NOT NULL,
+  house_number VARCHAR(50),
+  street TEXT,
+  unit VARCHAR(50),
+  city TEXT,
+  region TEXT,
+  postcode VARCHAR(20),
+  address TEXT NOT NULL,
+  location GEOGRAPHY(Point, 4326) NOT NULL,
+  search TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', address)) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_geocoder_addresses_location ON geocoder_addresses USING GIST (location);
+CREATE INDEX IF NOT EXISTS idx_geocoder_addresses_search ON geocoder_addresses USING GIN (search);
+
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS address TEXT;
+
+ALTER TABLE estimates ADD COLUMN IF NOT EXISTS user_address TEXT;
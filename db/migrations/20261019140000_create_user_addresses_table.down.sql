@@ -0,0 +1,6 @@
+ALTER TABLE estimates
+  DROP COLUMN IF EXISTS address_id,
+  DROP COLUMN IF EXISTS address_label,
+  DROP COLUMN IF EXISTS courier_notes;
+
+DROP TABLE IF EXISTS user_addresses;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS user_addresses (
+  id VARCHAR(26) PRIMARY KEY NOT NULL,
+  user_id VARCHAR(26) NOT NULL,
+  label VARCHAR(30) NOT NULL,
+  location GEOGRAPHY(Point, 4326) NOT NULL,
+  street TEXT,
+  notes TEXT,
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_addresses_user_id_label ON user_addresses (user_id, label);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_addresses_default ON user_addresses (user_id) WHERE is_default;
+
+-- the saved address an estimate was made for, copied so later edits don't change it
+ALTER TABLE estimates
+  ADD COLUMN IF NOT EXISTS address_id VARCHAR(26),
+  ADD COLUMN IF NOT EXISTS address_label VARCHAR(30),
+  ADD COLUMN IF NOT EXISTS courier_notes TEXT;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_items_name_trgm;
+DROP INDEX IF EXISTS idx_items_search;
+DROP INDEX IF EXISTS idx_merchants_name_trgm;
+DROP INDEX IF EXISTS idx_merchants_search;
+
+ALTER TABLE items DROP COLUMN IF EXISTS search;
+ALTER TABLE merchants DROP COLUMN IF EXISTS search;
+
+-- pg_trgm stays, it may have been installed before and other objects may use it
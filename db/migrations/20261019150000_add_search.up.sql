@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- categories are stored as SmallRestaurant and so on, split so "restaurant" matches
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS search TSVECTOR GENERATED ALWAYS AS (
+  setweight(to_tsvector('simple', name), 'A') ||
+  setweight(to_tsvector('simple', regexp_replace(COALESCE(category, ''), '([a-z])([A-Z])', '\1 \2', 'g')), 'B')
+) STORED;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS search TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', name)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_merchants_search ON merchants USING GIN (search);
+CREATE INDEX IF NOT EXISTS idx_merchants_name_trgm ON merchants USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_items_search ON items USING GIN (search);
+CREATE INDEX IF NOT EXISTS idx_items_name_trgm ON items USING GIN (name gin_trgm_ops);
@@ -0,0 +1,3 @@
+-- keyset pagination walks these in (created_at, id) order
+CREATE INDEX IF NOT EXISTS idx_merchants_created_at_id ON merchants (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_user_id_created_at_id ON orders (user_id, created_at, id);
@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// List returns the embedded migrations ordered by version.
+func List() ([]Migration, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		version, name, direction, ok := parseFilename(entry.Name())
+		if !ok {
+			continue
+		}
+
+		content, err := fs.ReadFile(FS, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, found := byVersion[version]
+		if !found {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		switch direction {
+		case "up":
+			m.Up = string(content)
+		case "down":
+			m.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// LatestVersion returns the highest migration version embedded in the binary.
+func LatestVersion() (uint64, error) {
+	list, err := List()
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+	return list[len(list)-1].Version, nil
+}
+
+// parseFilename splits names like 20240527133548_create_users_table.up.sql.
+func parseFilename(filename string) (version uint64, name, direction string, ok bool) {
+	base, found := strings.CutSuffix(filename, ".sql")
+	if !found {
+		return 0, "", "", false
+	}
+
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 {
+		return 0, "", "", false
+	}
+	base, direction = base[:dot], base[dot+1:]
+	if direction != "up" && direction != "down" {
+		return 0, "", "", false
+	}
+
+	prefix, name, found := strings.Cut(base, "_")
+	if !found {
+		return 0, "", "", false
+	}
+	version, err := strconv.ParseUint(prefix, 10, 64)
+	if err != nil {
+		return 0, "", "", false
+	}
+
+	return version, name, direction, true
+}
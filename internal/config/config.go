@@ -0,0 +1,436 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	App      AppConfig      `yaml:"app"`
+	DB       DBConfig       `yaml:"db"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Purchase PurchaseConfig `yaml:"purchase"`
+	Routing  RoutingConfig  `yaml:"routing"`
+	ETA      ETAConfig      `yaml:"eta"`
+	Geocoder GeocoderConfig `yaml:"geocoder"`
+	AWS      AWSConfig      `yaml:"aws"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	Log      LogConfig      `yaml:"log"`
+}
+
+type AppConfig struct {
+	Host              string        `yaml:"host" env:"APP_HOST"`
+	Port              int           `yaml:"port" env:"APP_PORT"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"APP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"APP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"APP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" env:"APP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout" env:"APP_SHUTDOWN_TIMEOUT"`
+	ShutdownDelay     time.Duration `yaml:"shutdownDelay" env:"APP_SHUTDOWN_DELAY"`
+	MaxHeaderBytes    int           `yaml:"maxHeaderBytes" env:"APP_MAX_HEADER_BYTES"`
+}
+
+type DBConfig struct {
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT"`
+	Username        string        `yaml:"username" env:"DB_USERNAME"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD"`
+	Params          string        `yaml:"params" env:"DB_PARAMS"`
+	MinConns        int           `yaml:"minConns" env:"DB_MIN_CONNS"`
+	MaxConns        int           `yaml:"maxConns" env:"DB_MAX_CONNS"`
+	MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime" env:"DB_MAX_CONN_IDLE_TIME"`
+	MaxConnLifetime time.Duration `yaml:"maxConnLifetime" env:"DB_MAX_CONN_LIFETIME"`
+	AutoMigrate     bool          `yaml:"autoMigrate" env:"DB_AUTO_MIGRATE"`
+}
+
+type AuthConfig struct {
+	JWTSecret  string        `yaml:"jwtSecret" env:"JWT_SECRET"`
+	TokenTTL   time.Duration `yaml:"tokenTtl" env:"JWT_TTL"`
+	BcryptCost int           `yaml:"bcryptCost" env:"BCRYPT_SALT"`
+}
+
+type PurchaseConfig struct {
+	MaxDistanceKm          float64       `yaml:"maxDistanceKm" env:"PURCHASE_MAX_DISTANCE_KM"`
+	NearbyRouteMaxKm       float64       `yaml:"nearbyRouteMaxKm" env:"PURCHASE_NEARBY_ROUTE_MAX_KM"`
+	NearbyRouteConcurrency int           `yaml:"nearbyRouteConcurrency" env:"PURCHASE_NEARBY_ROUTE_CONCURRENCY"`
+	NearbyRouteBudget      time.Duration `yaml:"nearbyRouteBudget" env:"PURCHASE_NEARBY_ROUTE_BUDGET"`
+}
+
+type RoutingConfig struct {
+	PBFPath          string  `yaml:"pbfPath" env:"ROUTING_PBF_PATH"`
+	MaxSnapDistanceM float64 `yaml:"maxSnapDistanceM" env:"ROUTING_MAX_SNAP_DISTANCE_M"`
+	FallbackSpeedKmh float64 `yaml:"fallbackSpeedKmh" env:"ROUTING_FALLBACK_SPEED_KMH"`
+}
+
+type ETAConfig struct {
+	DefaultPreparationTime time.Duration `yaml:"defaultPreparationTime" env:"ETA_DEFAULT_PREPARATION_TIME"`
+	PickupHandlingTime     time.Duration `yaml:"pickupHandlingTime" env:"ETA_PICKUP_HANDLING_TIME"`
+	Timezone               string        `yaml:"timezone" env:"ETA_TIMEZONE"`
+	RangeFraction          float64       `yaml:"rangeFraction" env:"ETA_RANGE_FRACTION"`
+	CalibrationInterval    time.Duration `yaml:"calibrationInterval" env:"ETA_CALIBRATION_INTERVAL"`
+	CalibrationWindow      time.Duration `yaml:"calibrationWindow" env:"ETA_CALIBRATION_WINDOW"`
+	CalibrationMinSamples  int           `yaml:"calibrationMinSamples" env:"ETA_CALIBRATION_MIN_SAMPLES"`
+}
+
+type GeocoderConfig struct {
+	Provider            string        `yaml:"provider" env:"GEOCODER_PROVIDER"`
+	NominatimURL        string        `yaml:"nominatimUrl" env:"GEOCODER_NOMINATIM_URL"`
+	UserAgent           string        `yaml:"userAgent" env:"GEOCODER_USER_AGENT"`
+	Timeout             time.Duration `yaml:"timeout" env:"GEOCODER_TIMEOUT"`
+	MaxReverseDistanceM float64       `yaml:"maxReverseDistanceM" env:"GEOCODER_MAX_REVERSE_DISTANCE_M"`
+}
+
+type AWSConfig struct {
+	AccessKeyID     string `yaml:"accessKeyId" env:"AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secretAccessKey" env:"AWS_SECRET_ACCESS_KEY"`
+	BucketName      string `yaml:"bucketName" env:"AWS_S3_BUCKET_NAME"`
+	Region          string `yaml:"region" env:"AWS_REGION"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	ServiceName string  `yaml:"serviceName" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sampleRatio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+type LogConfig struct {
+	Level      string  `yaml:"level" env:"LOG_LEVEL"`
+	Format     string  `yaml:"format" env:"LOG_FORMAT"`
+	AddSource  bool    `yaml:"addSource" env:"LOG_ADD_SOURCE"`
+	SampleRate float64 `yaml:"sampleRate" env:"LOG_SAMPLE_RATE"`
+}
+
+func (c *AppConfig) Addr() string {
+	return c.Host + ":" + strconv.Itoa(c.Port)
+}
+
+func (c *DBConfig) ConnString() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.Username, c.Password),
+		Host:     c.Host + ":" + strconv.Itoa(c.Port),
+		Path:     c.Name,
+		RawQuery: c.Params,
+	}
+	return u.String()
+}
+
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Host:              "localhost",
+			Port:              8080,
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+		},
+		DB: DBConfig{
+			Port:            5432,
+			Params:          "sslmode=disable",
+			MinConns:        10,
+			MaxConns:        20,
+			MaxConnIdleTime: 10 * time.Minute,
+			MaxConnLifetime: 60 * time.Minute,
+		},
+		Auth: AuthConfig{
+			TokenTTL:   2 * time.Hour,
+			BcryptCost: 10,
+		},
+		Purchase: PurchaseConfig{
+			MaxDistanceKm:          3,
+			NearbyRouteMaxKm:       10,
+			NearbyRouteConcurrency: 8,
+			NearbyRouteBudget:      500 * time.Millisecond,
+		},
+		Routing: RoutingConfig{
+			MaxSnapDistanceM: 500,
+			FallbackSpeedKmh: 40,
+		},
+		ETA: ETAConfig{
+			DefaultPreparationTime: 10 * time.Minute,
+			PickupHandlingTime:     3 * time.Minute,
+			Timezone:               "Asia/Jakarta",
+			RangeFraction:          0.2,
+			CalibrationInterval:    24 * time.Hour,
+			CalibrationWindow:      28 * 24 * time.Hour,
+			CalibrationMinSamples:  20,
+		},
+		Geocoder: GeocoderConfig{
+			Provider:            "postgis",
+			NominatimURL:        "https://nominatim.openstreetmap.org",
+			UserAgent:           "beli-mang",
+			Timeout:             2 * time.Second,
+			MaxReverseDistanceM: 100,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "beli-mang",
+			SampleRatio: 1,
+		},
+		Log: LogConfig{
+			Level:      "info",
+			Format:     "json",
+			SampleRate: 1,
+		},
+	}
+}
+
+// Read builds the configuration from the defaults, the optional YAML file at
+// path (or CONFIG_FILE) and finally the environment, including variables from
+// .env, each layer overriding the previous one. The result is not validated.
+func Read(path string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load .env file: %w", err)
+	}
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	cfg := Default()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(content, cfg); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks every section needed to run the API server.
+func (c *Config) Validate() error {
+	v := &validation{}
+	c.validateApp(v)
+	c.validateLog(v)
+	c.validateDB(v)
+	c.validateAuth(v)
+	c.validatePurchase(v)
+	c.validateRouting(v)
+	c.validateETA(v)
+	c.validateGeocoder(v)
+	c.validateTracing(v)
+	c.validateAWS(v)
+	return v.err()
+}
+
+// ValidateDB checks only what tools that talk to the database need.
+func (c *Config) ValidateDB() error {
+	v := &validation{}
+	c.validateLog(v)
+	c.validateDB(v)
+	return v.err()
+}
+
+// ValidateCtl checks what the admin CLI needs, the database, auth, ETA and
+// geocoder sections.
+func (c *Config) ValidateCtl() error {
+	v := &validation{}
+	c.validateLog(v)
+	c.validateDB(v)
+	c.validateAuth(v)
+	c.validateETA(v)
+	c.validateGeocoder(v)
+	return v.err()
+}
+
+type validation struct {
+	errs []error
+}
+
+func (v *validation) required(value, key string) {
+	if value == "" {
+		v.errs = append(v.errs, fmt.Errorf("%s is required", key))
+	}
+}
+
+func (v *validation) positive(value int64, key string) {
+	if value <= 0 {
+		v.errs = append(v.errs, fmt.Errorf("%s must be greater than 0", key))
+	}
+}
+
+func (v *validation) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *validation) err() error {
+	if len(v.errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(v.errs...))
+	}
+	return nil
+}
+
+func (c *Config) validateApp(v *validation) {
+	v.required(c.App.Host, "APP_HOST")
+	v.positive(int64(c.App.Port), "APP_PORT")
+	v.positive(int64(c.App.ReadTimeout), "APP_READ_TIMEOUT")
+	v.positive(int64(c.App.ReadHeaderTimeout), "APP_READ_HEADER_TIMEOUT")
+	v.positive(int64(c.App.WriteTimeout), "APP_WRITE_TIMEOUT")
+	v.positive(int64(c.App.IdleTimeout), "APP_IDLE_TIMEOUT")
+	v.positive(int64(c.App.ShutdownTimeout), "APP_SHUTDOWN_TIMEOUT")
+	v.positive(int64(c.App.MaxHeaderBytes), "APP_MAX_HEADER_BYTES")
+	v.check(c.App.ShutdownDelay >= 0, "APP_SHUTDOWN_DELAY must not be negative")
+}
+
+func (c *Config) validateLog(v *validation) {
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		v.check(false, "LOG_LEVEL must be one of debug, info, warn or error, got %q", c.Log.Level)
+	}
+	v.check(c.Log.Format == "json" || c.Log.Format == "text", "LOG_FORMAT must be json or text, got %q", c.Log.Format)
+	v.check(c.Log.SampleRate > 0 && c.Log.SampleRate <= 1, "LOG_SAMPLE_RATE must be greater than 0 and at most 1")
+}
+
+func (c *Config) validateDB(v *validation) {
+	v.required(c.DB.Name, "DB_NAME")
+	v.required(c.DB.Host, "DB_HOST")
+	v.required(c.DB.Username, "DB_USERNAME")
+	v.positive(int64(c.DB.Port), "DB_PORT")
+	v.positive(int64(c.DB.MaxConns), "DB_MAX_CONNS")
+	v.check(c.DB.MinConns >= 0 && c.DB.MinConns <= c.DB.MaxConns, "DB_MIN_CONNS must be between 0 and DB_MAX_CONNS (%d)", c.DB.MaxConns)
+}
+
+func (c *Config) validateAuth(v *validation) {
+	v.required(c.Auth.JWTSecret, "JWT_SECRET")
+	v.positive(int64(c.Auth.TokenTTL), "JWT_TTL")
+	v.check(c.Auth.BcryptCost >= 4 && c.Auth.BcryptCost <= 31, "BCRYPT_SALT must be between 4 and 31, got %d", c.Auth.BcryptCost)
+}
+
+func (c *Config) validatePurchase(v *validation) {
+	v.check(c.Purchase.MaxDistanceKm > 0, "PURCHASE_MAX_DISTANCE_KM must be greater than 0")
+	v.check(c.Purchase.NearbyRouteMaxKm >= 0, "PURCHASE_NEARBY_ROUTE_MAX_KM must not be negative")
+	v.positive(int64(c.Purchase.NearbyRouteConcurrency), "PURCHASE_NEARBY_ROUTE_CONCURRENCY")
+	v.positive(int64(c.Purchase.NearbyRouteBudget), "PURCHASE_NEARBY_ROUTE_BUDGET")
+}
+
+func (c *Config) validateRouting(v *validation) {
+	v.check(c.Routing.MaxSnapDistanceM > 0, "ROUTING_MAX_SNAP_DISTANCE_M must be greater than 0")
+	v.check(c.Routing.FallbackSpeedKmh > 0, "ROUTING_FALLBACK_SPEED_KMH must be greater than 0")
+}
+
+func (c *Config) validateETA(v *validation) {
+	v.check(c.ETA.DefaultPreparationTime >= 0, "ETA_DEFAULT_PREPARATION_TIME must not be negative")
+	v.check(c.ETA.PickupHandlingTime >= 0, "ETA_PICKUP_HANDLING_TIME must not be negative")
+	if _, err := time.LoadLocation(c.ETA.Timezone); err != nil {
+		v.check(false, "ETA_TIMEZONE must be an IANA time zone, got %q", c.ETA.Timezone)
+	}
+	v.check(c.ETA.RangeFraction >= 0 && c.ETA.RangeFraction < 1, "ETA_RANGE_FRACTION must be at least 0 and less than 1")
+	v.check(c.ETA.CalibrationInterval >= 0, "ETA_CALIBRATION_INTERVAL must not be negative")
+	v.positive(int64(c.ETA.CalibrationWindow), "ETA_CALIBRATION_WINDOW")
+	v.positive(int64(c.ETA.CalibrationMinSamples), "ETA_CALIBRATION_MIN_SAMPLES")
+}
+
+func (c *Config) validateGeocoder(v *validation) {
+	switch c.Geocoder.Provider {
+	case "none", "postgis":
+	case "nominatim":
+		v.required(c.Geocoder.NominatimURL, "GEOCODER_NOMINATIM_URL")
+		v.required(c.Geocoder.UserAgent, "GEOCODER_USER_AGENT")
+	default:
+		v.check(false, "GEOCODER_PROVIDER must be one of none, postgis or nominatim, got %q", c.Geocoder.Provider)
+	}
+	v.positive(int64(c.Geocoder.Timeout), "GEOCODER_TIMEOUT")
+	v.check(c.Geocoder.MaxReverseDistanceM > 0, "GEOCODER_MAX_REVERSE_DISTANCE_M must be greater than 0")
+}
+
+func (c *Config) validateTracing(v *validation) {
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		v.required(c.Tracing.Endpoint, "OTEL_EXPORTER_OTLP_ENDPOINT")
+	default:
+		v.check(false, "OTEL_TRACES_EXPORTER must be one of none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "OTEL_TRACES_SAMPLER_ARG must be between 0 and 1")
+}
+
+func (c *Config) validateAWS(v *validation) {
+	v.required(c.AWS.BucketName, "AWS_S3_BUCKET_NAME")
+	v.required(c.AWS.Region, "AWS_REGION")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		structField := t.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := structField.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(key)
+		if !ok || value == "" {
+			continue
+		}
+
+		switch {
+		case field.Type() == durationType:
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s: invalid duration %q", key, value)
+			}
+			field.SetInt(int64(d))
+		case field.Kind() == reflect.String:
+			field.SetString(value)
+		case field.Kind() == reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s: invalid integer %q", key, value)
+			}
+			field.SetInt(int64(n))
+		case field.Kind() == reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s: invalid boolean %q", key, value)
+			}
+			field.SetBool(b)
+		case field.Kind() == reflect.Float64:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s: invalid number %q", key, value)
+			}
+			field.SetFloat(f)
+		default:
+			return fmt.Errorf("%s: unsupported config field type %s", key, field.Type())
+		}
+	}
+	return nil
+}
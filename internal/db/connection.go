@@ -2,35 +2,26 @@ package db
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"time"
 
+	"github.com/danzBraham/beli-mang/internal/config"
+	"github.com/danzBraham/beli-mang/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func Connect() (*pgxpool.Pool, error) {
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?%s",
-		os.Getenv("DB_USERNAME"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PARAMS"),
-	)
-
-	config, err := pgxpool.ParseConfig(connString)
+func Connect(cfg config.DBConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.ConnString())
 	if err != nil {
 		return nil, err
 	}
 
-	config.MinConns = 10
-	config.MaxConns = 20
-	config.MaxConnIdleTime = 10 * time.Minute
-	config.MaxConnLifetime = 60 * time.Minute
+	poolConfig.MinConns = int32(cfg.MinConns)
+	poolConfig.MaxConns = int32(cfg.MaxConns)
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.ConnConfig.Tracer = tracing.NewQueryTracer()
 
 	ctx := context.Background()
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, err
 	}
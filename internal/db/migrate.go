@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/danzBraham/beli-mang/db/migrations"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockKey identifies the advisory lock held while migrating so
+// replicas starting at the same time apply migrations one after another.
+const migrationLockKey int64 = 7240531058437
+
+var ErrDirtySchema = errors.New("database schema is dirty, fix it manually and force the version before migrating")
+
+type MigrationStatus struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+type Migrator struct {
+	DB         *pgxpool.Pool
+	Migrations []migrations.Migration
+}
+
+func NewMigrator(db *pgxpool.Pool) (*Migrator, error) {
+	list, err := migrations.List()
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+	return &Migrator{DB: db, Migrations: list}, nil
+}
+
+// Up applies every pending migration and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			if migration.Version <= current {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration.Up, migration.Version, true); err != nil {
+				return fmt.Errorf("apply %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.InfoContext(ctx, "migration applied", "version", migration.Version, "name", migration.Name)
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the given number of applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.Migrations[i]
+			if migration.Version > current {
+				continue
+			}
+
+			var previous uint64
+			if i > 0 {
+				previous = m.Migrations[i-1].Version
+			}
+			if err := m.apply(ctx, conn, migration.Down, previous, i > 0); err != nil {
+				return fmt.Errorf("revert %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.InfoContext(ctx, "migration reverted", "version", migration.Version, "name", migration.Name)
+			current = previous
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports the current schema version, whether it is dirty and which
+// embedded migrations have been applied.
+func (m *Migrator) Status(ctx context.Context) (uint64, bool, []MigrationStatus, error) {
+	conn, err := m.DB.Acquire(ctx)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	defer conn.Release()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return 0, false, nil, err
+	}
+
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, false, nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		statuses = append(statuses, MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Version <= version,
+		})
+	}
+	return version, dirty, statuses, nil
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// use a fresh context so the lock is released even after cancellation
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			slog.Error("failed to release migration lock", "error", err)
+		}
+	}()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// apply runs the SQL and records the resulting version in one transaction, so
+// a failing migration leaves both the schema and the version untouched.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, sql string, version uint64, keepVersion bool) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if sql != "" {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if keepVersion {
+		query := `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`
+		if _, err := tx.Exec(ctx, query, int64(version)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ensureMigrationsTable creates the single-row version table used by
+// golang-migrate, so either tool can be used against the same database.
+func ensureMigrationsTable(ctx context.Context, conn *pgxpool.Conn) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY NOT NULL,
+		dirty BOOLEAN NOT NULL
+	)`
+	_, err := conn.Exec(ctx, query)
+	return err
+}
+
+func currentVersion(ctx context.Context, conn *pgxpool.Conn) (uint64, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w (version %d)", ErrDirtySchema, version)
+	}
+	return version, nil
+}
+
+func readVersion(ctx context.Context, conn *pgxpool.Conn) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	err := conn.QueryRow(ctx, query).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(version), dirty, nil
+}
@@ -0,0 +1,47 @@
+package address_entity
+
+import purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+
+// MaxAddresses is how many addresses one user can save.
+const MaxAddresses = 20
+
+type Address struct {
+	Id        string
+	UserId    string
+	Label     string
+	Location  purchase_entity.Location
+	Street    *string
+	Notes     *string
+	IsDefault bool
+	CreatedAt string
+	UpdatedAt string
+}
+
+// AddressRequest creates or replaces an address. Location may be left out
+// when Street can be geocoded.
+type AddressRequest struct {
+	Label     string                    `json:"label" validate:"required,max=30"`
+	Location  *purchase_entity.Location `json:"location" validate:"required_without=Street"`
+	Street    string                    `json:"street" validate:"omitempty,min=3,max=200"`
+	Notes     string                    `json:"notes" validate:"omitempty,max=500"`
+	IsDefault bool                      `json:"isDefault"`
+}
+
+type AddAddressResponse struct {
+	Id string `json:"addressId"`
+}
+
+type GetAddress struct {
+	Id        string                   `json:"addressId"`
+	Label     string                   `json:"label"`
+	Location  purchase_entity.Location `json:"location"`
+	Street    *string                  `json:"street"`
+	Notes     *string                  `json:"notes"`
+	IsDefault bool                     `json:"isDefault"`
+	CreatedAt string                   `json:"createdAt"`
+	UpdatedAt string                   `json:"updatedAt"`
+}
+
+type GetAddressesResponse struct {
+	Data []*GetAddress `json:"data"`
+}
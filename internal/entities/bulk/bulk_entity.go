@@ -0,0 +1,55 @@
+package bulk_entity
+
+import "github.com/danzBraham/beli-mang/internal/exceptions"
+
+const (
+	FormatCSV    string = "csv"
+	FormatNDJSON string = "ndjson"
+)
+
+const (
+	// ModeAtomic imports nothing unless every row is valid.
+	ModeAtomic string = "atomic"
+	// ModeBestEffort imports the valid rows and reports the others.
+	ModeBestEffort string = "bestEffort"
+)
+
+// MaxRows caps a single import so one request can't hold a transaction for
+// too long.
+const MaxRows = 10000
+
+type ImportOptions struct {
+	Mode     string
+	DryRun   bool
+	Language string
+}
+
+// Row is one decoded input row. Line is the 1-based line in the uploaded
+// file, Err is set when the row couldn't be decoded at all.
+type Row[T any] struct {
+	Line    int
+	Payload *T
+	Err     error
+}
+
+type RowError struct {
+	Line    int                     `json:"line"`
+	Message string                  `json:"message"`
+	Errors  []exceptions.FieldError `json:"errors,omitempty"`
+}
+
+type RowResult struct {
+	Line int    `json:"line"`
+	Id   string `json:"id"`
+}
+
+type ImportReport struct {
+	Mode     string      `json:"mode"`
+	DryRun   bool        `json:"dryRun"`
+	Total    int         `json:"total"`
+	Valid    int         `json:"valid"`
+	Imported int         `json:"imported"`
+	Failed   int         `json:"failed"`
+	Created  []RowResult `json:"created"`
+	Errors   []RowError  `json:"errors"`
+}
@@ -0,0 +1,48 @@
+package eta_entity
+
+import "time"
+
+// HoursPerWeek is the number of hour-of-week buckets, Monday 00:00 is hour 0.
+const HoursPerWeek = 7 * 24
+
+// SpeedProfile scales routed travel times for one hour of the week. A factor
+// of 1 means traffic moves as fast as the router assumes, 0.5 means trips take
+// twice as long. FactorLow and FactorHigh bound the usual spread.
+type SpeedProfile struct {
+	HourOfWeek int     `json:"hourOfWeek"`
+	Factor     float64 `json:"factor"`
+	FactorLow  float64 `json:"factorLow"`
+	FactorHigh float64 `json:"factorHigh"`
+	Samples    int     `json:"samples"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+// Trip is what an estimate needs to know about a delivery.
+type Trip struct {
+	// Travel is the routed duration of the longest leg to the customer.
+	Travel time.Duration
+	// PreparationMinutes has one entry per merchant, nil when the merchant
+	// has no preparation time of its own.
+	PreparationMinutes []*int
+}
+
+type Estimate struct {
+	Minutes            int
+	MinMinutes         int
+	MaxMinutes         int
+	PreparationMinutes int
+}
+
+// Delivery is a completed order as the calibration sees it.
+type Delivery struct {
+	OrderedAt            time.Time
+	DeliveredAt          time.Time
+	RouteDurationSeconds int
+	PreparationMinutes   int
+}
+
+type CalibrationReport struct {
+	Deliveries int `json:"deliveries"`
+	Used       int `json:"used"`
+	Hours      int `json:"hours"`
+}
@@ -0,0 +1,48 @@
+package geo_entity
+
+import "encoding/json"
+
+const (
+	TypeFeatureCollection string = "FeatureCollection"
+	TypeFeature           string = "Feature"
+	TypePoint             string = "Point"
+	TypePolygon           string = "Polygon"
+	TypeMultiPolygon      string = "MultiPolygon"
+)
+
+// BBox is a viewport in WGS 84 degrees, as in a GeoJSON bbox.
+type BBox struct {
+	MinLong float64
+	MinLat  float64
+	MaxLong float64
+	MaxLat  float64
+}
+
+// Feature is a GeoJSON feature. Geometry is kept raw since PostGIS already
+// renders it with ST_AsGeoJSON.
+type Feature struct {
+	Type       string          `json:"type"`
+	Id         string          `json:"id,omitempty"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties any             `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+func NewFeatureCollection(features []*Feature) *FeatureCollection {
+	return &FeatureCollection{Type: TypeFeatureCollection, Features: features}
+}
+
+type point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// NewPoint renders a GeoJSON point; note GeoJSON puts longitude first.
+func NewPoint(lat, long float64) json.RawMessage {
+	geometry, _ := json.Marshal(&point{Type: TypePoint, Coordinates: [2]float64{long, lat}})
+	return geometry
+}
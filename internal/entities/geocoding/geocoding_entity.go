@@ -0,0 +1,58 @@
+package geocoding_entity
+
+import (
+	"strings"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+// Place is a geocoding result, a formatted address and where it is.
+type Place struct {
+	Address  string                   `json:"address"`
+	Location purchase_entity.Location `json:"location"`
+}
+
+// Address is one entry of the local geocoding dataset.
+type Address struct {
+	HouseNumber string
+	Street      string
+	Unit        string
+	City        string
+	Region      string
+	Postcode    string
+	Location    purchase_entity.Location
+}
+
+// Format renders the address the way it is searched and shown, e.g.
+// "Jalan Sudirman 5, Jakarta Pusat, DKI Jakarta 10220". Empty parts are left
+// out.
+func (a *Address) Format() string {
+	parts := []string{}
+	appendPart := func(values ...string) {
+		part := ""
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if part != "" {
+				part += " "
+			}
+			part += value
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	appendPart(a.Street, a.HouseNumber)
+	appendPart(a.Unit)
+	appendPart(a.City)
+	appendPart(a.Region, a.Postcode)
+	return strings.Join(parts, ", ")
+}
+
+type ImportReport struct {
+	Imported int64 `json:"imported"`
+	Skipped  int   `json:"skipped"`
+	Replaced bool  `json:"replaced"`
+}
@@ -0,0 +1,16 @@
+package health_entity
+
+const (
+	StatusUp   string = "up"
+	StatusDown string = "down"
+)
+
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+type HealthResponse struct {
+	Status       string                       `json:"status"`
+	Dependencies map[string]*DependencyStatus `json:"dependencies,omitempty"`
+}
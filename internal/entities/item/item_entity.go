@@ -1,5 +1,7 @@
 package item_entity
 
+import pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+
 const (
 	Beverage   string = "Beverage"
 	Food       string = "Food"
@@ -17,6 +19,8 @@ type Item struct {
 	MerchantId string
 	CreatedAt  string
 	UpdatedAt  string
+	// Position is where the item sits in the list it was read from.
+	Position pagination_entity.Cursor
 }
 
 type AddItemRequest struct {
@@ -26,19 +30,44 @@ type AddItemRequest struct {
 	ImageURL string `json:"imageUrl" validate:"required,imageurl"`
 }
 
+type ImportItemRequest struct {
+	MerchantId string `json:"merchantId" validate:"required"`
+	Name       string `json:"name" validate:"required,min=2,max=30"`
+	Category   string `json:"productCategory" validate:"oneof='Beverage' 'Food' 'Snack' 'Condiments' 'Additions'"`
+	Price      int    `json:"price" validate:"required,min=1"`
+	ImageURL   string `json:"imageUrl" validate:"required,imageurl"`
+}
+
+type ExportItem struct {
+	Id         string `json:"itemId"`
+	MerchantId string `json:"merchantId"`
+	Name       string `json:"name"`
+	Category   string `json:"productCategory"`
+	Price      int    `json:"price"`
+	ImageURL   string `json:"imageUrl"`
+	CreatedAt  string `json:"createdAt"`
+}
+
 type AddItemResponse struct {
 	Id string `json:"itemId"`
 }
 
 type ItemQueryParams struct {
 	Id        string
-	Limit     int
-	Offset    int
+	Page      *pagination_entity.Page
 	Name      string
 	Category  string
 	CreatedAt string
 }
 
+// MerchantItemsParams filters the items listed under each merchant. Limit
+// caps the items per merchant, zero means no limit.
+type MerchantItemsParams struct {
+	Limit    int
+	Name     string
+	Category string
+}
+
 type GetItem struct {
 	Id        string `json:"itemId"`
 	Name      string `json:"name"`
@@ -49,9 +78,11 @@ type GetItem struct {
 }
 
 type Meta struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
-	Total  int `json:"total"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	Total      int     `json:"total"`
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
 }
 
 type GetItemResponse struct {
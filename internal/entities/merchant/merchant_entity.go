@@ -1,5 +1,13 @@
 package merchant_entity
 
+import (
+	"encoding/json"
+	"math"
+
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+)
+
 const (
 	SmallRestaurant       string = "SmallRestaurant"
 	MediumRestaurant      string = "MediumRestaurant"
@@ -15,21 +23,39 @@ type Location struct {
 }
 
 type Merchant struct {
-	Id        string
-	Name      string
-	Category  string
-	ImageURL  string
-	Location  Location
-	UserId    string
-	CreatedAt string
-	UpdatedAt string
+	Id       string
+	Name     string
+	Category string
+	ImageURL string
+	Location Location
+	UserId   string
+	// Address is the street address, nil when the merchant was created from
+	// coordinates alone.
+	Address *string
+	// PreparationMinutes is the average time to prepare an order, nil when
+	// the default applies.
+	PreparationMinutes *int
+	CreatedAt          string
+	UpdatedAt          string
+	// Position is where the merchant sits in the list it was read from.
+	Position pagination_entity.Cursor
 }
 
+// AddMerchantRequest places the merchant at Location, or at Address when
+// only the address is sent.
 type AddMerchantRequest struct {
-	Name     string   `json:"name" validate:"required,min=2,max=30"`
-	Category string   `json:"merchantCategory" validate:"oneof='SmallRestaurant' 'MediumRestaurant' 'LargeRestaurant' 'MerchandiseRestaurant' 'BoothKiosk' 'ConvenienceStore'"`
-	ImageURL string   `json:"imageUrl" validate:"required,imageurl"`
-	Location Location `json:"location"`
+	Name               string    `json:"name" validate:"required,min=2,max=30"`
+	Category           string    `json:"merchantCategory" validate:"oneof='SmallRestaurant' 'MediumRestaurant' 'LargeRestaurant' 'MerchandiseRestaurant' 'BoothKiosk' 'ConvenienceStore'"`
+	ImageURL           string    `json:"imageUrl" validate:"required,imageurl"`
+	Location           *Location `json:"location" validate:"required_without=Address"`
+	Address            string    `json:"address" validate:"omitempty,min=3,max=200"`
+	PreparationMinutes *int      `json:"preparationTimeInMinutes" validate:"omitempty,min=1,max=240"`
+}
+
+// Pickup is what an estimate needs to know about a merchant.
+type Pickup struct {
+	Location           Location
+	PreparationMinutes *int
 }
 
 type AddMerchantResponse struct {
@@ -38,8 +64,7 @@ type AddMerchantResponse struct {
 
 type MerchantQueryParams struct {
 	Id        string
-	Limit     int
-	Offset    int
+	Page      *pagination_entity.Page
 	Name      string
 	Category  string
 	CreatedAt string
@@ -51,16 +76,93 @@ type GetMerchant struct {
 	Category  string   `json:"merchantCategory"`
 	ImageURL  string   `json:"imageUrl"`
 	Location  Location `json:"location"`
+	Address   *string  `json:"address,omitempty"`
 	CreatedAt string   `json:"createdAt"`
 }
 
+// ExportMerchant is a merchant as exported, with every column an import
+// accepts.
+type ExportMerchant struct {
+	GetMerchant
+	PreparationMinutes *int `json:"preparationTimeInMinutes"`
+}
+
 type Meta struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
-	Total  int `json:"total"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	Total      int     `json:"total"`
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
 }
 
 type GetMerchantResponse struct {
 	Data []*GetMerchant `json:"data"`
 	Meta Meta           `json:"meta"`
 }
+
+const (
+	// DefaultWithinLimit and MaxWithinLimit bound how many merchants or
+	// clusters a viewport returns.
+	DefaultWithinLimit = 500
+	MaxWithinLimit     = 2000
+	// MinZoom and MaxZoom are the web map zoom levels accepted. Clustering
+	// stops at MaxClusterZoom, where points are close to street level.
+	MinZoom        = 0
+	MaxZoom        = 22
+	MaxClusterZoom = 16
+	// ClusterRadiusPixels is how wide a cluster cell is on screen.
+	ClusterRadiusPixels = 60
+
+	TypeMerchant string = "merchant"
+	TypeCluster  string = "cluster"
+)
+
+// MerchantWithinQueryParams selects merchants inside BBox and Polygon; at
+// least one of them is set. Polygon is a validated GeoJSON geometry.
+type MerchantWithinQueryParams struct {
+	BBox     *geo_entity.BBox
+	Polygon  json.RawMessage
+	Name     string
+	Category string
+	Limit    int
+	// Zoom enables clustering below MaxClusterZoom when set.
+	Zoom *int
+}
+
+// ClusterCellDegrees is the grid size clustering snaps points to at zoom,
+// one ClusterRadiusPixels cell of a 256 pixel web map tile.
+func ClusterCellDegrees(zoom int) float64 {
+	return 360 / math.Exp2(float64(zoom)) * ClusterRadiusPixels / 256
+}
+
+// MerchantCluster is a group of merchants in one grid cell. Merchant is
+// only set when the cluster holds a single merchant.
+type MerchantCluster struct {
+	Count    int
+	Location Location
+	Merchant *Merchant
+}
+
+type MerchantWithin struct {
+	Type     string       `json:"type"`
+	Location Location     `json:"location"`
+	Count    int          `json:"count"`
+	Merchant *GetMerchant `json:"merchant,omitempty"`
+}
+
+type WithinMeta struct {
+	Count     int  `json:"count"`
+	Clustered bool `json:"clustered"`
+	Truncated bool `json:"truncated"`
+}
+
+type GetMerchantsWithinResponse struct {
+	Data []*MerchantWithin `json:"data"`
+	Meta WithinMeta        `json:"meta"`
+}
+
+// ClusterProperties are the GeoJSON properties of a cluster feature.
+type ClusterProperties struct {
+	Cluster    bool `json:"cluster"`
+	PointCount int  `json:"pointCount"`
+}
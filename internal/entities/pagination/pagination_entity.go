@@ -0,0 +1,34 @@
+package pagination_entity
+
+import "time"
+
+const (
+	DefaultLimit int = 5
+	MaxLimit     int = 100
+
+	SortCreatedAtAsc  string = "createdAt:asc"
+	SortCreatedAtDesc string = "createdAt:desc"
+)
+
+// Page selects one page of a list. In offset mode Cursor is nil; in cursor
+// mode Offset is zero and the page starts right after (or, read backward,
+// right before) the row Cursor points at.
+type Page struct {
+	Limit  int
+	Offset int
+	// Sort names the order the list is read in, a cursor is only valid for
+	// the sort it was issued for.
+	Sort   string
+	Cursor *Cursor
+}
+
+// Cursor is the position of a row in a sorted list, sent to clients as an
+// opaque string. Only the keys of the sort are set.
+type Cursor struct {
+	Sort      string     `json:"s"`
+	Backward  bool       `json:"b,omitempty"`
+	Id        string     `json:"i"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	Distance  *float64   `json:"d,omitempty"`
+	Name      *string    `json:"n,omitempty"`
+}
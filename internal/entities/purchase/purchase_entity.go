@@ -3,25 +3,52 @@ package purchase_entity
 import (
 	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
 	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+)
+
+const (
+	SortByDistance string = "distance"
+	SortByNewest   string = "newest"
+	SortByName     string = "name"
+	// MaxNearbyDistanceKm bounds the maxDistance a nearby search may ask for.
+	MaxNearbyDistanceKm float64 = 50
+	// DefaultNearbyItemLimit and MaxNearbyItemLimit bound the items listed
+	// under each nearby merchant.
+	DefaultNearbyItemLimit int = 5
+	MaxNearbyItemLimit     int = 20
 )
 
 type MerchantNearbyQueryParams struct {
 	Id       string
-	Limit    int
-	Offset   int
+	Page     *pagination_entity.Page
 	Name     string
 	Category string
+	// MaxDistance in kilometers, zero means no limit.
+	MaxDistance float64
+	Sort        string
+	Items       item_entity.MerchantItemsParams
+}
+
+type MerchantNearby struct {
+	Merchant           *merchant_entity.GetMerchant
+	DistanceKm         float64
+	PreparationMinutes *int
+	Position           pagination_entity.Cursor
 }
 
 type GetMerchantsNearby struct {
-	Merchant *merchant_entity.GetMerchant `json:"merchant"`
-	Items    []*item_entity.GetItem       `json:"items"`
+	Merchant     *merchant_entity.GetMerchant `json:"merchant"`
+	Items        []*item_entity.GetItem       `json:"items"`
+	DistanceKm   float64                      `json:"distanceKm"`
+	DeliveryTime int                          `json:"estimatedDeliveryTimeInMinutes"`
 }
 
 type Meta struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
-	Total  int `json:"total"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	Total      int     `json:"total"`
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
 }
 
 type GetMerchantsNearbyResponse struct {
@@ -37,7 +64,7 @@ type Location struct {
 
 type Item struct {
 	Id       string `json:"itemId" validate:"required"`
-	Quantity int    `json:"quantity" validate:"required"`
+	Quantity int    `json:"quantity" validate:"required,min=1"`
 }
 
 type Order struct {
@@ -46,24 +73,68 @@ type Order struct {
 	Items           []Item `json:"items" validate:"required,dive"`
 }
 
+// UserEstimateRequest delivers to UserLocation, or to the saved address
+// AddressId, or to the user's default address when both are left out.
 type UserEstimateRequest struct {
-	UserLocation Location `json:"userLocation" validate:"required"`
-	Orders       []Order  `json:"orders" validate:"required,onestartingpoint,dive"`
+	UserLocation *Location `json:"userLocation"`
+	AddressId    string    `json:"addressId" validate:"excluded_with=UserLocation"`
+	Orders       []Order   `json:"orders" validate:"required,onestartingpoint,dive"`
+}
+
+type DeliveryTimeRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
 }
 
 type UserEstimateResponse struct {
-	TotalPrice      int    `json:"totalPrice"`
-	DeliveryTime    int    `json:"estimatedDeliveryTimeInMinutes"`
-	EstimateOrderId string `json:"calculatedEstimateId"`
+	TotalPrice        int               `json:"totalPrice"`
+	DeliveryTime      int               `json:"estimatedDeliveryTimeInMinutes"`
+	DeliveryTimeRange DeliveryTimeRange `json:"estimatedDeliveryTimeRangeInMinutes"`
+	UserAddress       *string           `json:"userAddress,omitempty"`
+	EstimateOrderId   string            `json:"calculatedEstimateId"`
 }
 
 type EstimateOrder struct {
+	Id           string
+	UserLocation Location
+	UserAddress  *string // street of the saved address or the nearest one found
+	// the saved address delivered to, copied at estimate time
+	AddressId             *string
+	AddressLabel          *string
+	CourierNotes          *string
+	TotalPrice            int
+	EstimatedDeliveryTime int
+	// the route and preparation the delivery time was based on, kept to
+	// calibrate speed profiles against the actual delivery
+	RouteDistanceKm      float64
+	RouteDurationSeconds int
+	PreparationMinutes   int
+	CreatedAt            string
+	UpdatedAt            string
+}
+
+const (
+	EstimateOpen    string = "open"
+	EstimateOrdered string = "ordered"
+	EstimateVoided  string = "voided"
+)
+
+type EstimateState struct {
 	Id                    string
-	UserLocation          Location
 	TotalPrice            int
 	EstimatedDeliveryTime int
+	IsOrdered             bool
+	IsVoided              bool
 	CreatedAt             string
-	UpdatedAt             string
+}
+
+type GetEstimate struct {
+	Id                 string `json:"estimateId"`
+	TotalPrice         int    `json:"totalPrice"`
+	PreviousTotalPrice *int   `json:"previousTotalPrice,omitempty"`
+	DeliveryTime       int    `json:"estimatedDeliveryTimeInMinutes"`
+	Status             string `json:"status"`
+	CreatedAt          string `json:"createdAt"`
 }
 
 type OrderMerchant struct {
@@ -100,10 +171,14 @@ type UserOrderResponse struct {
 	OrderId string `json:"orderId"`
 }
 
+type OrderDeliveredResponse struct {
+	OrderId     string `json:"orderId"`
+	DeliveredAt string `json:"deliveredAt"`
+}
+
 type OrderQueryParams struct {
 	MerchantId string
-	Limit      int
-	Offset     int
+	Page       *pagination_entity.Page
 	Name       string
 	Category   string
 }
@@ -136,3 +211,21 @@ type GetUserOrder struct {
 	OrderId string     `json:"orderId"`
 	Orders  []GetOrder `json:"orders"`
 }
+
+type UserOrderHistory struct {
+	Order    *GetUserOrder
+	Position pagination_entity.Cursor
+}
+
+type OrderMeta struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
+}
+
+// GetUserOrdersResponse is the order history in cursor mode, offset mode
+// keeps answering with the bare list of orders.
+type GetUserOrdersResponse struct {
+	Data []*GetUserOrder `json:"data"`
+	Meta OrderMeta       `json:"meta"`
+}
@@ -0,0 +1,75 @@
+package search_entity
+
+import (
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+const (
+	MinQueryLength   int = 2
+	MaxQueryLength   int = 100
+	DefaultLimit     int = 5
+	MaxLimit         int = 50
+	DefaultItemLimit int = 3
+	MaxItemLimit     int = 20
+	// ItemMatchWeight scales how much a matching item counts towards its
+	// merchant, a merchant matching by name ranks above one selling a match
+	ItemMatchWeight float64 = 0.8
+	// DistanceDecayKm is how far away a merchant's relevance halves, so a
+	// close good match beats a far perfect one
+	DistanceDecayKm float64 = 3
+	// HighlightStart and HighlightStop wrap the matched words of an item
+	// name, the rest of which is HTML escaped
+	HighlightStart string = "<mark>"
+	HighlightStop  string = "</mark>"
+)
+
+type SearchQueryParams struct {
+	Query    string
+	Category string
+	// Location is nil when the caller sent none, results then rank by
+	// relevance alone.
+	Location  *purchase_entity.Location
+	Limit     int
+	Offset    int
+	ItemLimit int
+}
+
+type MerchantMatch struct {
+	Merchant   *merchant_entity.GetMerchant
+	DistanceKm *float64
+	Relevance  float64
+	Score      float64
+}
+
+type ItemMatch struct {
+	Item *item_entity.Item
+	// Highlight is the HTML escaped item name with the words that matched
+	// the query wrapped in HighlightStart and HighlightStop. A name only
+	// found by similarity has nothing wrapped.
+	Highlight string
+}
+
+type SearchItem struct {
+	item_entity.GetItem
+	Highlight string `json:"highlightedName"`
+}
+
+type SearchResult struct {
+	Merchant   *merchant_entity.GetMerchant `json:"merchant"`
+	Items      []*SearchItem                `json:"items"`
+	DistanceKm *float64                     `json:"distanceKm,omitempty"`
+	Score      float64                      `json:"score"`
+}
+
+type Meta struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+type SearchResponse struct {
+	Data []*SearchResult `json:"data"`
+	Meta Meta            `json:"meta"`
+}
@@ -1,11 +1,13 @@
 package user_entity
 
 type User struct {
-	Id       string
-	Username string
-	Password string
-	Email    string
-	IsAdmin  bool
+	Id         string
+	Username   string
+	Password   string
+	Email      string
+	IsAdmin    bool
+	IsDisabled bool
+	CreatedAt  string
 }
 
 type RegisterUserRequest struct {
@@ -26,3 +28,23 @@ type LoginUserRequest struct {
 type LoginUserResponse struct {
 	Token string `json:"token"`
 }
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" validate:"required,min=5,max=30"`
+}
+
+type UserQueryParams struct {
+	Limit    int
+	Offset   int
+	Username string
+	Role     string
+}
+
+type GetUser struct {
+	Id         string `json:"userId"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	IsAdmin    bool   `json:"isAdmin"`
+	IsDisabled bool   `json:"isDisabled"`
+	CreatedAt  string `json:"createdAt"`
+}
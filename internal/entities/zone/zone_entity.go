@@ -0,0 +1,46 @@
+package zone_entity
+
+import (
+	"time"
+
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
+)
+
+const (
+	MinPrecision     int = 1
+	MaxPrecision     int = 8
+	DefaultPrecision int = 6
+	// MaxCells bounds a response, a fine precision over a wide area has to
+	// be narrowed with a bbox
+	MaxCells int = 10000
+)
+
+type ZoneQueryParams struct {
+	// Precision is the geohash length, 6 is about 1.2 x 0.6 km.
+	Precision int
+	BBox      *geo_entity.BBox
+	// From and To limit the orders by when they were placed.
+	From *time.Time
+	To   *time.Time
+}
+
+type Zone struct {
+	Geohash  string
+	Geometry []byte
+	// Merchants located in the cell.
+	Merchants int
+	// Orders delivered to customers in the cell.
+	Orders                       int
+	AverageBasket                *float64
+	AverageDeliveryTime          *float64
+	AverageEstimatedDeliveryTime *float64
+}
+
+type ZoneProperties struct {
+	Geohash                      string   `json:"geohash"`
+	Merchants                    int      `json:"merchantCount"`
+	Orders                       int      `json:"orderCount"`
+	AverageBasket                *float64 `json:"averageBasket"`
+	AverageDeliveryTime          *float64 `json:"averageDeliveryTimeInMinutes"`
+	AverageEstimatedDeliveryTime *float64 `json:"averageEstimatedDeliveryTimeInMinutes"`
+}
@@ -0,0 +1,9 @@
+package address_exception
+
+import "errors"
+
+var (
+	ErrAddressIdNotFound  = errors.New("address id is not found")
+	ErrLabelAlreadyExists = errors.New("an address with this label already exists")
+	ErrTooManyAddresses   = errors.New("address book is full, remove an address first")
+)
@@ -6,4 +6,6 @@ var (
 	ErrMissingToken  = errors.New("missing token")
 	ErrInvalidToken  = errors.New("invalid token")
 	ErrUnknownClaims = errors.New("unknown claims type")
+	ErrNotAdmin      = errors.New("you're not admin")
+	ErrNotUser       = errors.New("you're not a user")
 )
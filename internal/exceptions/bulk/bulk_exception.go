@@ -0,0 +1,13 @@
+package bulk_exception
+
+import "errors"
+
+var (
+	ErrUnsupportedFormat = errors.New("format must be csv or ndjson")
+	ErrInvalidMode       = errors.New("mode must be atomic or bestEffort")
+	ErrInvalidDryRun     = errors.New("dryRun must be true or false")
+	ErrMissingColumns    = errors.New("csv header is missing required columns")
+	ErrTooManyRows       = errors.New("too many rows in one import")
+	ErrEmptyImport       = errors.New("import contains no rows")
+	ErrFileTooLarge      = errors.New("import file is too large")
+)
@@ -0,0 +1,188 @@
+package exceptions
+
+import (
+	"errors"
+	"net/http"
+
+	address_exception "github.com/danzBraham/beli-mang/internal/exceptions/address"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
+	bulk_exception "github.com/danzBraham/beli-mang/internal/exceptions/bulk"
+	geo_exception "github.com/danzBraham/beli-mang/internal/exceptions/geo"
+	item_exception "github.com/danzBraham/beli-mang/internal/exceptions/item"
+	media_exception "github.com/danzBraham/beli-mang/internal/exceptions/media"
+	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
+	pagination_exception "github.com/danzBraham/beli-mang/internal/exceptions/pagination"
+	purchase_exception "github.com/danzBraham/beli-mang/internal/exceptions/purchase"
+	search_exception "github.com/danzBraham/beli-mang/internal/exceptions/search"
+	user_exception "github.com/danzBraham/beli-mang/internal/exceptions/user"
+	zone_exception "github.com/danzBraham/beli-mang/internal/exceptions/zone"
+)
+
+// AppError is an error that knows how it must be presented to API clients.
+// Message is safe to expose, Cause is kept for logs only.
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Errors  []FieldError
+	Cause   error
+}
+
+// FieldError describes one invalid input field. Field is the JSON path of the
+// field, e.g. "orders[1].items[0].quantity".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func New(status int, code, message string) *AppError {
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+func (e *AppError) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Message + ": " + e.Cause.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches any AppError with the same code, so copies returned by
+// WithCause and WithMessage still match their sentinel.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+func (e *AppError) WithCause(cause error) *AppError {
+	c := *e
+	c.Cause = cause
+	return &c
+}
+
+func (e *AppError) WithMessage(message string) *AppError {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+func (e *AppError) WithErrors(errs []FieldError) *AppError {
+	c := *e
+	c.Errors = errs
+	return &c
+}
+
+var (
+	ErrInternal         = New(http.StatusInternalServerError, "internal_error", "Something went wrong")
+	ErrInvalidJSON      = New(http.StatusBadRequest, "invalid_json", "Request body is not valid JSON")
+	ErrValidation       = New(http.StatusBadRequest, "validation_failed", "Request doesn't pass validation")
+	ErrRouteNotFound    = New(http.StatusNotFound, "route_not_found", "Route does not exist")
+	ErrMethodNotAllowed = New(http.StatusMethodNotAllowed, "method_not_allowed", "Method is not allowed")
+)
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// mappings translates the domain sentinels into HTTP problems. Their
+// messages are written for clients and are exposed as is.
+var mappings = []mapping{
+	{auth_exception.ErrMissingToken, http.StatusUnauthorized, "missing_token"},
+	{auth_exception.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+	{auth_exception.ErrUnknownClaims, http.StatusUnauthorized, "unknown_claims"},
+	{auth_exception.ErrNotAdmin, http.StatusForbidden, "not_admin"},
+	{auth_exception.ErrNotUser, http.StatusForbidden, "not_user"},
+
+	{user_exception.ErrUsernameAlreadyExists, http.StatusConflict, "username_already_exists"},
+	{user_exception.ErrAdminEmailAlreadyExists, http.StatusConflict, "admin_email_already_exists"},
+	{user_exception.ErrUserEmailAlreadyExists, http.StatusConflict, "user_email_already_exists"},
+	{user_exception.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{user_exception.ErrInvalidPassword, http.StatusBadRequest, "invalid_password"},
+	{user_exception.ErrUserDisabled, http.StatusForbidden, "user_disabled"},
+
+	{merchant_exception.ErrMerchantIdNotFound, http.StatusNotFound, "merchant_not_found"},
+	{merchant_exception.ErrInvalidWithinLimit, http.StatusBadRequest, "invalid_limit"},
+
+	{item_exception.ErrItemIdNotFound, http.StatusNotFound, "item_not_found"},
+	{item_exception.ErrItemNotInMerchant, http.StatusBadRequest, "item_not_in_merchant"},
+
+	{purchase_exception.ErrDistanceTooFar, http.StatusBadRequest, "distance_too_far"},
+	{purchase_exception.ErrEstimateIdNotFound, http.StatusNotFound, "estimate_not_found"},
+	{purchase_exception.ErrInvalidLocation, http.StatusBadRequest, "invalid_location"},
+	{purchase_exception.ErrInvalidMaxDistance, http.StatusBadRequest, "invalid_max_distance"},
+	{purchase_exception.ErrInvalidItemLimit, http.StatusBadRequest, "invalid_item_limit"},
+	{purchase_exception.ErrEstimateVoided, http.StatusConflict, "estimate_voided"},
+	{purchase_exception.ErrEstimateOrdered, http.StatusConflict, "estimate_already_ordered"},
+	{purchase_exception.ErrMissingLocation, http.StatusBadRequest, "missing_user_location"},
+	{purchase_exception.ErrOrderIdNotFound, http.StatusNotFound, "order_not_found"},
+	{purchase_exception.ErrOrderDelivered, http.StatusConflict, "order_already_delivered"},
+	{purchase_exception.ErrInvalidDeliveredAt, http.StatusBadRequest, "invalid_delivered_at"},
+
+	{pagination_exception.ErrInvalidLimit, http.StatusBadRequest, "invalid_limit"},
+	{pagination_exception.ErrInvalidOffset, http.StatusBadRequest, "invalid_offset"},
+	{pagination_exception.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{pagination_exception.ErrCursorWithOffset, http.StatusBadRequest, "cursor_with_offset"},
+
+	{bulk_exception.ErrUnsupportedFormat, http.StatusUnsupportedMediaType, "unsupported_format"},
+	{bulk_exception.ErrInvalidMode, http.StatusBadRequest, "invalid_import_mode"},
+	{bulk_exception.ErrInvalidDryRun, http.StatusBadRequest, "invalid_dry_run"},
+	{bulk_exception.ErrMissingColumns, http.StatusBadRequest, "missing_columns"},
+	{bulk_exception.ErrTooManyRows, http.StatusRequestEntityTooLarge, "too_many_rows"},
+	{bulk_exception.ErrEmptyImport, http.StatusBadRequest, "empty_import"},
+	{bulk_exception.ErrFileTooLarge, http.StatusRequestEntityTooLarge, "file_too_large"},
+
+	{address_exception.ErrAddressIdNotFound, http.StatusNotFound, "saved_address_not_found"},
+	{address_exception.ErrLabelAlreadyExists, http.StatusConflict, "address_label_already_exists"},
+	{address_exception.ErrTooManyAddresses, http.StatusConflict, "too_many_addresses"},
+
+	{geo_exception.ErrInvalidBBox, http.StatusBadRequest, "invalid_bbox"},
+	{geo_exception.ErrInvalidPolygon, http.StatusBadRequest, "invalid_polygon"},
+	{geo_exception.ErrMissingArea, http.StatusBadRequest, "missing_area"},
+	{geo_exception.ErrInvalidZoom, http.StatusBadRequest, "invalid_zoom"},
+	{geo_exception.ErrAddressNotFound, http.StatusBadRequest, "address_not_found"},
+	{geo_exception.ErrGeocoderUnavailable, http.StatusServiceUnavailable, "geocoder_unavailable"},
+
+	{search_exception.ErrInvalidQuery, http.StatusBadRequest, "invalid_query"},
+	{search_exception.ErrInvalidLimit, http.StatusBadRequest, "invalid_limit"},
+	{search_exception.ErrInvalidOffset, http.StatusBadRequest, "invalid_offset"},
+	{search_exception.ErrInvalidItemLimit, http.StatusBadRequest, "invalid_item_limit"},
+	{search_exception.ErrInvalidLocation, http.StatusBadRequest, "invalid_location"},
+
+	{zone_exception.ErrInvalidPrecision, http.StatusBadRequest, "invalid_precision"},
+	{zone_exception.ErrInvalidTimeRange, http.StatusBadRequest, "invalid_time_range"},
+	{zone_exception.ErrTooManyCells, http.StatusBadRequest, "too_many_cells"},
+
+	{media_exception.ErrInvalidForm, http.StatusBadRequest, "invalid_multipart_form"},
+	{media_exception.ErrMissingFile, http.StatusBadRequest, "missing_file"},
+	{media_exception.ErrInvalidFileType, http.StatusBadRequest, "invalid_file_type"},
+	{media_exception.ErrInvalidFileSize, http.StatusBadRequest, "invalid_file_size"},
+}
+
+// Translate maps any error to the AppError presented to clients. Unknown
+// errors become ErrInternal so their details are never exposed.
+func Translate(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return &AppError{
+				Status:  m.status,
+				Code:    m.code,
+				Message: m.err.Error(),
+				Cause:   err,
+			}
+		}
+	}
+
+	return ErrInternal.WithCause(err)
+}
@@ -0,0 +1,12 @@
+package geo_exception
+
+import "errors"
+
+var (
+	ErrInvalidBBox         = errors.New("bbox must be minLong,minLat,maxLong,maxLat in degrees with each min below its max")
+	ErrInvalidPolygon      = errors.New("body must be a GeoJSON Polygon or MultiPolygon, or a Feature holding one, with closed rings in degrees")
+	ErrMissingArea         = errors.New("either a bbox or a GeoJSON polygon is required")
+	ErrInvalidZoom         = errors.New("zoom must be an integer between 0 and 22")
+	ErrAddressNotFound     = errors.New("address could not be found, send its location instead")
+	ErrGeocoderUnavailable = errors.New("addresses cannot be looked up right now, send the location instead")
+)
@@ -0,0 +1,11 @@
+package health_exception
+
+import "errors"
+
+var (
+	ErrPostGISNotInstalled  = errors.New("postgis extension is not installed")
+	ErrMigrationsNotApplied = errors.New("schema_migrations table is missing")
+	ErrMigrationsDirty      = errors.New("last migration failed and left the schema dirty")
+	ErrMigrationsOutdated   = errors.New("database schema is behind the embedded migrations")
+	ErrShuttingDown         = errors.New("server is shutting down")
+)
@@ -2,4 +2,7 @@ package item_exception
 
 import "errors"
 
-var ErrItemIdNotFound = errors.New("item id is not found")
+var (
+	ErrItemIdNotFound    = errors.New("item id is not found")
+	ErrItemNotInMerchant = errors.New("item does not belong to the merchant it was ordered from")
+)
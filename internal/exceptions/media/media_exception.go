@@ -0,0 +1,10 @@
+package media_exception
+
+import "errors"
+
+var (
+	ErrInvalidForm     = errors.New("unable to parse form")
+	ErrMissingFile     = errors.New("unable to get file from form")
+	ErrInvalidFileType = errors.New("file must be in .jpg or .jpeg format")
+	ErrInvalidFileSize = errors.New("file size must be between 10KB and 2MB")
+)
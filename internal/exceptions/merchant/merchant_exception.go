@@ -2,4 +2,7 @@ package merchant_exception
 
 import "errors"
 
-var ErrMerchantIdNotFound = errors.New("merchant id is not found")
+var (
+	ErrMerchantIdNotFound = errors.New("merchant id is not found")
+	ErrInvalidWithinLimit = errors.New("limit must be an integer between 1 and 2000")
+)
@@ -0,0 +1,10 @@
+package pagination_exception
+
+import "errors"
+
+var (
+	ErrInvalidLimit     = errors.New("limit must be a whole number from 1 to 100")
+	ErrInvalidOffset    = errors.New("offset must be a whole number, 0 or more")
+	ErrInvalidCursor    = errors.New("cursor is not valid for this list, start again without it")
+	ErrCursorWithOffset = errors.New("send either cursor or offset, not both")
+)
@@ -5,4 +5,13 @@ import "errors"
 var (
 	ErrDistanceTooFar     = errors.New("the distance is too far")
 	ErrEstimateIdNotFound = errors.New("estimate id is not found")
+	ErrInvalidLocation    = errors.New("location is not valid")
+	ErrInvalidMaxDistance = errors.New("maxDistance must be a number of kilometers greater than 0 and at most 50")
+	ErrInvalidItemLimit   = errors.New("itemLimit must be a whole number from 0 to 20")
+	ErrEstimateVoided     = errors.New("estimate has been voided")
+	ErrEstimateOrdered    = errors.New("estimate has already been ordered")
+	ErrMissingLocation    = errors.New("send userLocation or addressId, or save a default address")
+	ErrOrderIdNotFound    = errors.New("order id is not found")
+	ErrOrderDelivered     = errors.New("order has already been delivered")
+	ErrInvalidDeliveredAt = errors.New("delivery time must be between the order time and now")
 )
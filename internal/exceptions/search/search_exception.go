@@ -0,0 +1,11 @@
+package search_exception
+
+import "errors"
+
+var (
+	ErrInvalidQuery     = errors.New("q must be 2 to 100 characters")
+	ErrInvalidLimit     = errors.New("limit must be a whole number from 1 to 50")
+	ErrInvalidOffset    = errors.New("offset must be a whole number, not negative")
+	ErrInvalidItemLimit = errors.New("itemLimit must be a whole number from 0 to 20")
+	ErrInvalidLocation  = errors.New("lat and long must be sent together as coordinates in degrees")
+)
@@ -8,4 +8,5 @@ var (
 	ErrUserEmailAlreadyExists  = errors.New("user email already exists")
 	ErrUserNotFound            = errors.New("user not found")
 	ErrInvalidPassword         = errors.New("invalid password")
+	ErrUserDisabled            = errors.New("user is disabled")
 )
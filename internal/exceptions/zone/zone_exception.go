@@ -0,0 +1,9 @@
+package zone_exception
+
+import "errors"
+
+var (
+	ErrInvalidPrecision = errors.New("precision must be a whole number from 1 to 8")
+	ErrInvalidTimeRange = errors.New("from and to must be RFC 3339 times with from before to")
+	ErrTooManyCells     = errors.New("too many cells, narrow the bbox or lower the precision")
+)
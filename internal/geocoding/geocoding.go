@@ -0,0 +1,110 @@
+// Package geocoding turns addresses into locations and locations back into
+// addresses. The default provider searches an address dataset imported into
+// PostGIS so lookups never leave the service, and an external provider can
+// be configured in its place.
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+	geocoding_entity "github.com/danzBraham/beli-mang/internal/entities/geocoding"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/metrics"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+)
+
+var (
+	ErrNotFound = errors.New("no place matches")
+	ErrDisabled = errors.New("geocoding is disabled")
+)
+
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (*geocoding_entity.Place, error)
+	Reverse(ctx context.Context, location purchase_entity.Location) (*geocoding_entity.Place, error)
+}
+
+// New returns the geocoder cfg.Provider names, bounded by cfg.Timeout and
+// counted in the geocoder metrics.
+func New(cfg config.GeocoderConfig, repository repositories.GeocoderRepository) (Geocoder, error) {
+	var geocoder Geocoder
+	switch cfg.Provider {
+	case "postgis":
+		geocoder = NewPostGISGeocoder(repository, cfg.MaxReverseDistanceM)
+	case "nominatim":
+		geocoder = NewNominatimGeocoder(cfg.NominatimURL, cfg.UserAgent, cfg.MaxReverseDistanceM)
+	case "none":
+		geocoder = NewDisabledGeocoder()
+	default:
+		return nil, fmt.Errorf("unknown geocoder provider %q", cfg.Provider)
+	}
+	return NewInstrumentedGeocoder(cfg.Provider, cfg.Timeout, geocoder), nil
+}
+
+// DisabledGeocoder refuses every lookup.
+type DisabledGeocoder struct{}
+
+func NewDisabledGeocoder() Geocoder {
+	return &DisabledGeocoder{}
+}
+
+func (g *DisabledGeocoder) Geocode(ctx context.Context, address string) (*geocoding_entity.Place, error) {
+	return nil, ErrDisabled
+}
+
+func (g *DisabledGeocoder) Reverse(ctx context.Context, location purchase_entity.Location) (*geocoding_entity.Place, error) {
+	return nil, ErrDisabled
+}
+
+// InstrumentedGeocoder bounds every lookup of Geocoder by Timeout and
+// counts its result.
+type InstrumentedGeocoder struct {
+	Provider string
+	Timeout  time.Duration
+	Geocoder Geocoder
+}
+
+func NewInstrumentedGeocoder(provider string, timeout time.Duration, geocoder Geocoder) Geocoder {
+	return &InstrumentedGeocoder{Provider: provider, Timeout: timeout, Geocoder: geocoder}
+}
+
+func (g *InstrumentedGeocoder) Geocode(ctx context.Context, address string) (*geocoding_entity.Place, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
+	defer cancel()
+
+	place, err := g.Geocoder.Geocode(ctx, address)
+	g.observe(ctx, "geocode", err)
+	return place, err
+}
+
+func (g *InstrumentedGeocoder) Reverse(ctx context.Context, location purchase_entity.Location) (*geocoding_entity.Place, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.Timeout)
+	defer cancel()
+
+	place, err := g.Geocoder.Reverse(ctx, location)
+	g.observe(ctx, "reverse", err)
+	return place, err
+}
+
+func (g *InstrumentedGeocoder) observe(ctx context.Context, operation string, err error) {
+	result := "ok"
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotFound):
+		result = "not_found"
+	case errors.Is(err, ErrDisabled):
+		result = "disabled"
+	case errors.Is(err, context.DeadlineExceeded):
+		result = "timeout"
+	default:
+		result = "error"
+	}
+	metrics.GeocoderRequests.WithLabelValues(g.Provider, operation, result).Inc()
+	if result == "timeout" || result == "error" {
+		logger.FromContext(ctx).Warn("geocoder lookup failed", "provider", g.Provider, "operation", operation, "error", err)
+	}
+}
@@ -0,0 +1,118 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	geocoding_entity "github.com/danzBraham/beli-mang/internal/entities/geocoding"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	formula_helper "github.com/danzBraham/beli-mang/internal/helpers/formula"
+)
+
+// NominatimGeocoder asks a Nominatim server, either a self-hosted one or
+// the public OSM instance within its usage policy.
+type NominatimGeocoder struct {
+	BaseURL             string
+	UserAgent           string
+	MaxReverseDistanceM float64
+	Client              *http.Client
+}
+
+func NewNominatimGeocoder(baseURL, userAgent string, maxReverseDistanceM float64) Geocoder {
+	return &NominatimGeocoder{
+		BaseURL:             strings.TrimSuffix(baseURL, "/"),
+		UserAgent:           userAgent,
+		MaxReverseDistanceM: maxReverseDistanceM,
+		Client:              http.DefaultClient,
+	}
+}
+
+type nominatimPlace struct {
+	Lat         string `json:"lat"`
+	Long        string `json:"lon"`
+	DisplayName string `json:"display_name"`
+	Error       string `json:"error"`
+}
+
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address string) (*geocoding_entity.Place, error) {
+	query := url.Values{"q": {address}, "format": {"jsonv2"}, "limit": {"1"}}
+
+	var places []nominatimPlace
+	if err := g.get(ctx, "/search", query, &places); err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+	return places[0].place()
+}
+
+func (g *NominatimGeocoder) Reverse(ctx context.Context, location purchase_entity.Location) (*geocoding_entity.Place, error) {
+	query := url.Values{
+		"lat":    {strconv.FormatFloat(location.Lat, 'f', -1, 64)},
+		"lon":    {strconv.FormatFloat(location.Long, 'f', -1, 64)},
+		"format": {"jsonv2"},
+		"zoom":   {"18"},
+	}
+
+	var result nominatimPlace
+	if err := g.get(ctx, "/reverse", query, &result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, ErrNotFound
+	}
+	place, err := result.place()
+	if err != nil {
+		return nil, err
+	}
+
+	// Nominatim answers with whatever is nearest, however far
+	if formula_helper.Distance(location, place.Location)*1000 > g.MaxReverseDistanceM {
+		return nil, ErrNotFound
+	}
+	return place, nil
+}
+
+func (g *NominatimGeocoder) get(ctx context.Context, path string, query url.Values, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.BaseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := g.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("nominatim %s: unexpected status %s", path, res.Status)
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("nominatim %s: decode response: %w", path, err)
+	}
+	return nil
+}
+
+func (p *nominatimPlace) place() (*geocoding_entity.Place, error) {
+	lat, err := strconv.ParseFloat(p.Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim: invalid latitude %q", p.Lat)
+	}
+	long, err := strconv.ParseFloat(p.Long, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim: invalid longitude %q", p.Long)
+	}
+	return &geocoding_entity.Place{
+		Address:  p.DisplayName,
+		Location: purchase_entity.Location{Lat: lat, Long: long},
+	}, nil
+}
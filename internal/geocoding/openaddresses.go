@@ -0,0 +1,113 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	geocoding_entity "github.com/danzBraham/beli-mang/internal/entities/geocoding"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+)
+
+// ImportOpenAddresses loads an OpenAddresses CSV extract into the dataset
+// PostGISGeocoder searches. Rows without a street or valid coordinates are
+// skipped.
+func ImportOpenAddresses(ctx context.Context, repository repositories.GeocoderRepository, r io.Reader, replace bool) (*geocoding_entity.ImportReport, error) {
+	reader, err := NewOpenAddressesReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := repository.ImportAddresses(ctx, reader.Next, replace)
+	if err != nil {
+		return nil, err
+	}
+	return &geocoding_entity.ImportReport{
+		Imported: imported,
+		Skipped:  reader.Skipped,
+		Replaced: replace,
+	}, nil
+}
+
+// OpenAddressesReader reads the LON,LAT,NUMBER,STREET,UNIT,CITY,DISTRICT,
+// REGION,POSTCODE columns of an OpenAddresses CSV, in any order.
+type OpenAddressesReader struct {
+	Skipped int
+	csv     *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+func NewOpenAddressesReader(r io.Reader) (*OpenAddressesReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"LON", "LAT", "STREET"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	return &OpenAddressesReader{csv: reader, columns: columns, line: 1}, nil
+}
+
+// Next returns the next usable address, or io.EOF after the last one.
+func (r *OpenAddressesReader) Next() (*geocoding_entity.Address, error) {
+	for {
+		record, err := r.csv.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		r.line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", r.line, err)
+		}
+
+		field := func(name string) string {
+			i, ok := r.columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		lat, latErr := strconv.ParseFloat(field("LAT"), 64)
+		long, longErr := strconv.ParseFloat(field("LON"), 64)
+		street := field("STREET")
+		if latErr != nil || longErr != nil || street == "" ||
+			lat < -90 || lat > 90 || long < -180 || long > 180 {
+			r.Skipped++
+			continue
+		}
+
+		city := field("CITY")
+		if city == "" {
+			city = field("DISTRICT")
+		}
+
+		return &geocoding_entity.Address{
+			HouseNumber: field("NUMBER"),
+			Street:      street,
+			Unit:        field("UNIT"),
+			City:        city,
+			Region:      field("REGION"),
+			Postcode:    field("POSTCODE"),
+			Location:    purchase_entity.Location{Lat: lat, Long: long},
+		}, nil
+	}
+}
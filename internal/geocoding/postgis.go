@@ -0,0 +1,41 @@
+package geocoding
+
+import (
+	"context"
+
+	geocoding_entity "github.com/danzBraham/beli-mang/internal/entities/geocoding"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+)
+
+// PostGISGeocoder searches the addresses imported with ImportOpenAddresses.
+type PostGISGeocoder struct {
+	Repository          repositories.GeocoderRepository
+	MaxReverseDistanceM float64
+}
+
+func NewPostGISGeocoder(repository repositories.GeocoderRepository, maxReverseDistanceM float64) Geocoder {
+	return &PostGISGeocoder{Repository: repository, MaxReverseDistanceM: maxReverseDistanceM}
+}
+
+func (g *PostGISGeocoder) Geocode(ctx context.Context, address string) (*geocoding_entity.Place, error) {
+	place, err := g.Repository.Geocode(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if place == nil {
+		return nil, ErrNotFound
+	}
+	return place, nil
+}
+
+func (g *PostGISGeocoder) Reverse(ctx context.Context, location purchase_entity.Location) (*geocoding_entity.Place, error) {
+	place, err := g.Repository.Reverse(ctx, location, g.MaxReverseDistanceM)
+	if err != nil {
+		return nil, err
+	}
+	if place == nil {
+		return nil, ErrNotFound
+	}
+	return place, nil
+}
@@ -1,18 +1,11 @@
 package bcrypt_helper
 
 import (
-	"os"
-	"strconv"
-
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashPassword(password string) (string, error) {
-	salt, err := strconv.Atoi(os.Getenv("BCRYPT_SALT"))
-	if err != nil {
-		return "", err
-	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), salt)
+func HashPassword(password string, cost int) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), cost)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,214 @@
+package bulk_helper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	bulk_entity "github.com/danzBraham/beli-mang/internal/entities/bulk"
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	bulk_exception "github.com/danzBraham/beli-mang/internal/exceptions/bulk"
+)
+
+// maxLineSize bounds a single NDJSON line.
+const maxLineSize = 1 << 20
+
+// DetectFormat picks the format from the format query parameter, falling
+// back to the Content-Type header for imports and to CSV for exports.
+func DetectFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if format != bulk_entity.FormatCSV && format != bulk_entity.FormatNDJSON {
+			return "", bulk_exception.ErrUnsupportedFormat
+		}
+		return format, nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if r.Method == http.MethodGet || contentType == "" {
+		return bulk_entity.FormatCSV, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", bulk_exception.ErrUnsupportedFormat
+	}
+	switch mediaType {
+	case "text/csv":
+		return bulk_entity.FormatCSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return bulk_entity.FormatNDJSON, nil
+	}
+	return "", bulk_exception.ErrUnsupportedFormat
+}
+
+func ContentType(format string) string {
+	if format == bulk_entity.FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Decode reads every row of r. CSV records are keyed by their header and
+// turned into payloads by fromRecord, NDJSON lines are unmarshalled as is.
+// Problems with a single row are reported on that row, only unreadable input
+// fails the whole decode.
+func Decode[T any](r io.Reader, format string, columns []string, fromRecord func(record map[string]string) (*T, error)) ([]bulk_entity.Row[T], error) {
+	var rows []bulk_entity.Row[T]
+	var err error
+	switch format {
+	case bulk_entity.FormatCSV:
+		rows, err = decodeCSV(r, columns, fromRecord)
+	case bulk_entity.FormatNDJSON:
+		rows, err = decodeNDJSON[T](r)
+	default:
+		return nil, bulk_exception.ErrUnsupportedFormat
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return nil, bulk_exception.ErrFileTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, bulk_exception.ErrEmptyImport
+	}
+	return rows, nil
+}
+
+func decodeCSV[T any](r io.Reader, columns []string, fromRecord func(record map[string]string) (*T, error)) ([]bulk_entity.Row[T], error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, bulk_exception.ErrEmptyImport
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", bulk_exception.ErrMissingColumns, err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	var missing []string
+	for _, column := range columns {
+		if !slices.Contains(header, column) {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		message := bulk_exception.ErrMissingColumns.Error() + ": " + strings.Join(missing, ", ")
+		return nil, exceptions.Translate(bulk_exception.ErrMissingColumns).WithMessage(message)
+	}
+
+	rows := []bulk_entity.Row[T]{}
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(rows) == bulk_entity.MaxRows {
+			return nil, bulk_exception.ErrTooManyRows
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, bulk_entity.Row[T]{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(values) != len(header) {
+			rows = append(rows, bulk_entity.Row[T]{Line: line, Err: fmt.Errorf("expected %d fields, got %d", len(header), len(values))})
+			continue
+		}
+
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			record[column] = strings.TrimSpace(values[i])
+		}
+		payload, err := fromRecord(record)
+		rows = append(rows, bulk_entity.Row[T]{Line: line, Payload: payload, Err: err})
+	}
+
+	return rows, nil
+}
+
+func decodeNDJSON[T any](r io.Reader) ([]bulk_entity.Row[T], error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	rows := []bulk_entity.Row[T]{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		content := bytes.TrimSpace(scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+		if len(rows) == bulk_entity.MaxRows {
+			return nil, bulk_exception.ErrTooManyRows
+		}
+
+		// unknown fields are ignored so exported files can be imported again
+		payload := new(T)
+		if err := json.Unmarshal(content, payload); err != nil {
+			rows = append(rows, bulk_entity.Row[T]{Line: line, Err: fmt.Errorf("invalid JSON: %w", err)})
+			continue
+		}
+		rows = append(rows, bulk_entity.Row[T]{Line: line, Payload: payload})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Writer encodes exported rows as CSV records or NDJSON lines.
+type Writer struct {
+	format string
+	csv    *csv.Writer
+	json   *json.Encoder
+}
+
+func NewWriter(w io.Writer, format string, header []string) (*Writer, error) {
+	writer := &Writer{format: format}
+	if format == bulk_entity.FormatNDJSON {
+		writer.json = json.NewEncoder(w)
+		return writer, nil
+	}
+
+	writer.csv = csv.NewWriter(w)
+	return writer, writer.csv.Write(header)
+}
+
+// Write adds one row, record for CSV and v for NDJSON.
+func (w *Writer) Write(v any, record []string) error {
+	if w.json != nil {
+		return w.json.Encode(v)
+	}
+	return w.csv.Write(record)
+}
+
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
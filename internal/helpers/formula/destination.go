@@ -0,0 +1,26 @@
+package formula_helper
+
+import (
+	"math"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+// Destination returns the point reached by travelling distance kilometers
+// from origin along the given bearing in degrees, clockwise from north.
+func Destination(origin purchase_entity.Location, bearing, distance float64) purchase_entity.Location {
+	const R = 6371 // Earth radius in kilometers
+	lat1, lon1 := origin.Lat*math.Pi/180, origin.Long*math.Pi/180
+	theta := bearing * math.Pi / 180
+	delta := distance / R
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	lon2 := lon1 + math.Atan2(
+		math.Sin(theta)*math.Sin(delta)*math.Cos(lat1),
+		math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2),
+	)
+
+	// normalise the longitude to [-180, 180)
+	long := math.Mod(lon2*180/math.Pi+540, 360) - 180
+	return purchase_entity.Location{Lat: lat2 * 180 / math.Pi, Long: long}
+}
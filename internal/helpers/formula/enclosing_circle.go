@@ -0,0 +1,179 @@
+package formula_helper
+
+import (
+	"math"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+// earthRadius is the mean Earth radius in kilometers, the same one Distance uses.
+const earthRadius = 6371
+
+// epsilon is the angular tolerance, in radians, for a point to count as inside
+// a circle. It is about 6 mm on the ground.
+const epsilon = 1e-9
+
+// Circle represents a circle on the Earth's surface by its center and its
+// radius in kilometers along the surface.
+type Circle struct {
+	Center purchase_entity.Location
+	Radius float64
+}
+
+// vector is a point on the unit sphere.
+type vector struct {
+	x, y, z float64
+}
+
+// cap is a spherical circle, a center on the unit sphere and an angular radius.
+type cap struct {
+	center vector
+	angle  float64
+}
+
+func toVector(p purchase_entity.Location) vector {
+	lat, long := p.Lat*math.Pi/180, p.Long*math.Pi/180
+	return vector{
+		x: math.Cos(lat) * math.Cos(long),
+		y: math.Cos(lat) * math.Sin(long),
+		z: math.Sin(lat),
+	}
+}
+
+func (v vector) toLocation() purchase_entity.Location {
+	return purchase_entity.Location{
+		Lat:  math.Atan2(v.z, math.Hypot(v.x, v.y)) * 180 / math.Pi,
+		Long: math.Atan2(v.y, v.x) * 180 / math.Pi,
+	}
+}
+
+func (v vector) add(w vector) vector    { return vector{v.x + w.x, v.y + w.y, v.z + w.z} }
+func (v vector) sub(w vector) vector    { return vector{v.x - w.x, v.y - w.y, v.z - w.z} }
+func (v vector) dot(w vector) float64   { return v.x*w.x + v.y*w.y + v.z*w.z }
+func (v vector) norm() float64          { return math.Sqrt(v.dot(v)) }
+func (v vector) scale(f float64) vector { return vector{v.x * f, v.y * f, v.z * f} }
+
+func (v vector) cross(w vector) vector {
+	return vector{
+		v.y*w.z - v.z*w.y,
+		v.z*w.x - v.x*w.z,
+		v.x*w.y - v.y*w.x,
+	}
+}
+
+// angle returns the central angle between two unit vectors. atan2 keeps it
+// accurate for both tiny and near antipodal angles, unlike acos.
+func angle(v, w vector) float64 {
+	return math.Atan2(v.cross(w).norm(), v.dot(w))
+}
+
+func (c cap) contains(p vector) bool {
+	return angle(c.center, p) <= c.angle+epsilon
+}
+
+// capFromTwo returns the smallest cap with both points on its boundary.
+func capFromTwo(a, b vector) cap {
+	mid := a.add(b)
+	if mid.norm() < epsilon {
+		// antipodal points, any great circle through both will do
+		return cap{center: orthogonal(a), angle: math.Pi / 2}
+	}
+	center := mid.scale(1 / mid.norm())
+	return cap{center: center, angle: angle(center, a)}
+}
+
+// capFromThree returns the cap whose boundary passes through all three
+// points, or the widest two point cap when they lie on one great circle.
+func capFromThree(a, b, c vector) cap {
+	normal := b.sub(a).cross(c.sub(a))
+	if normal.norm() < epsilon*epsilon {
+		widest := capFromTwo(a, b)
+		for _, candidate := range []cap{capFromTwo(a, c), capFromTwo(b, c)} {
+			if candidate.angle > widest.angle {
+				widest = candidate
+			}
+		}
+		return widest
+	}
+
+	center := normal.scale(1 / normal.norm())
+	// of the two poles of the plane through the points, use the closer one
+	if center.dot(a) < 0 {
+		center = center.scale(-1)
+	}
+	return cap{center: center, angle: angle(center, a)}
+}
+
+// orthogonal returns a unit vector perpendicular to v.
+func orthogonal(v vector) vector {
+	axis := vector{1, 0, 0}
+	if math.Abs(v.x) > 0.9 {
+		axis = vector{0, 1, 0}
+	}
+	w := v.cross(axis)
+	return w.scale(1 / w.norm())
+}
+
+// SmallestEnclosingCircle returns the smallest circle on the sphere that
+// contains every point. It works on unit vectors, so it stays correct near
+// the poles and across the antimeridian, and it is deterministic: the same
+// points always give the same circle.
+//
+// The result is exact as long as the points fit in a hemisphere, which any
+// deliverable order does. Otherwise a circle that still contains every point
+// is returned, though it may not be the smallest.
+func SmallestEnclosingCircle(points []purchase_entity.Location) Circle {
+	if len(points) == 0 {
+		return Circle{}
+	}
+
+	vectors := make([]vector, len(points))
+	for i, p := range points {
+		vectors[i] = toVector(p)
+	}
+
+	// iterative Welzl without the random shuffle, the inputs are small
+	// enough that its cubic worst case doesn't matter
+	c := cap{center: vectors[0]}
+	for i := 1; i < len(vectors); i++ {
+		if c.contains(vectors[i]) {
+			continue
+		}
+		c = cap{center: vectors[i]}
+		for j := 0; j < i; j++ {
+			if c.contains(vectors[j]) {
+				continue
+			}
+			c = capFromTwo(vectors[i], vectors[j])
+			for k := 0; k < j; k++ {
+				if !c.contains(vectors[k]) {
+					c = capFromThree(vectors[i], vectors[j], vectors[k])
+				}
+			}
+		}
+	}
+
+	if !containsAll(c, vectors) {
+		c = farthestFrom(vectors[0], vectors)
+	}
+
+	return Circle{Center: c.center.toLocation(), Radius: c.angle * earthRadius}
+}
+
+func containsAll(c cap, vectors []vector) bool {
+	for _, v := range vectors {
+		if !c.contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// farthestFrom returns the cap around center reaching the farthest point.
+func farthestFrom(center vector, vectors []vector) cap {
+	c := cap{center: center}
+	for _, v := range vectors {
+		c.angle = math.Max(c.angle, angle(center, v))
+	}
+	return c
+}
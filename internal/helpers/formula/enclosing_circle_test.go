@@ -0,0 +1,148 @@
+package formula_helper
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+// tolerance is how far, in kilometers, a point may sit outside the circle.
+const tolerance = 1e-6
+
+// cluster is a random set of nearby points anywhere on Earth, including near
+// the poles and across the antimeridian.
+type cluster []purchase_entity.Location
+
+func (cluster) Generate(r *rand.Rand, size int) reflect.Value {
+	center := purchase_entity.Location{
+		Lat:  r.Float64()*180 - 90,
+		Long: r.Float64()*360 - 180,
+	}
+	spread := r.Float64() * 50
+	points := make(cluster, 1+r.Intn(20))
+	for i := range points {
+		points[i] = Destination(center, r.Float64()*360, r.Float64()*spread)
+	}
+	return reflect.ValueOf(points)
+}
+
+func quickConfig() *quick.Config {
+	return &quick.Config{MaxCount: 500, Rand: rand.New(rand.NewSource(1))}
+}
+
+func TestSmallestEnclosingCircleContainsEveryPoint(t *testing.T) {
+	property := func(points cluster) bool {
+		circle := SmallestEnclosingCircle(points)
+		for _, p := range points {
+			if Distance(circle.Center, p) > circle.Radius+tolerance {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSmallestEnclosingCircleIsDeterministic(t *testing.T) {
+	property := func(points cluster) bool {
+		return SmallestEnclosingCircle(points) == SmallestEnclosingCircle(points)
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSmallestEnclosingCircleIsMinimal(t *testing.T) {
+	// the radius can't be less than half the widest pair, and the circle
+	// around any single point reaching all others is never smaller
+	property := func(points cluster) bool {
+		circle := SmallestEnclosingCircle(points)
+		widest, around := 0.0, math.Inf(1)
+		for _, p := range points {
+			farthest := 0.0
+			for _, q := range points {
+				farthest = math.Max(farthest, Distance(p, q))
+			}
+			widest = math.Max(widest, farthest)
+			around = math.Min(around, farthest)
+		}
+		return circle.Radius >= widest/2-tolerance && circle.Radius <= around+tolerance
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSmallestEnclosingCircleIgnoresOrder(t *testing.T) {
+	property := func(points cluster, seed int64) bool {
+		shuffled := append(cluster{}, points...)
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		a, b := SmallestEnclosingCircle(points), SmallestEnclosingCircle(shuffled)
+		return math.Abs(a.Radius-b.Radius) < tolerance && Distance(a.Center, b.Center) < 1e-3
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSmallestEnclosingCircle(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []purchase_entity.Location
+		radius float64
+	}{
+		{
+			name:   "single point",
+			points: []purchase_entity.Location{{Lat: -6.2088, Long: 106.8456}},
+			radius: 0,
+		},
+		{
+			name: "across the antimeridian",
+			points: []purchase_entity.Location{
+				{Lat: 0, Long: 179.99},
+				{Lat: 0, Long: -179.99},
+			},
+			radius: Distance(purchase_entity.Location{Lat: 0, Long: 179.99}, purchase_entity.Location{Lat: 0, Long: -179.99}) / 2,
+		},
+		{
+			name: "around the north pole",
+			points: []purchase_entity.Location{
+				{Lat: 89.99, Long: 0},
+				{Lat: 89.99, Long: 120},
+				{Lat: 89.99, Long: -120},
+			},
+			radius: Distance(purchase_entity.Location{Lat: 89.99, Long: 0}, purchase_entity.Location{Lat: 90, Long: 0}),
+		},
+		{
+			name: "right triangle",
+			points: []purchase_entity.Location{
+				{Lat: 0, Long: 0},
+				{Lat: 0, Long: 0.02},
+				{Lat: 0.02, Long: 0},
+			},
+			radius: Distance(purchase_entity.Location{Lat: 0, Long: 0.02}, purchase_entity.Location{Lat: 0.02, Long: 0}) / 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			circle := SmallestEnclosingCircle(tt.points)
+			if math.Abs(circle.Radius-tt.radius) > 1e-3 {
+				t.Errorf("radius = %f km, want %f km", circle.Radius, tt.radius)
+			}
+			for _, p := range tt.points {
+				if d := Distance(circle.Center, p); d > circle.Radius+tolerance {
+					t.Errorf("point %v is %f km from the center, outside radius %f km", p, d, circle.Radius)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package geo_helper
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	geo_exception "github.com/danzBraham/beli-mang/internal/exceptions/geo"
+)
+
+const (
+	// MaxPolygonBytes bounds a polygon request body.
+	MaxPolygonBytes = 1 << 20
+	// MaxPolygonPositions bounds the vertices PostGIS has to test against.
+	MaxPolygonPositions = 10000
+)
+
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometry    *geometry       `json:"geometry,omitempty"`
+}
+
+// ReadPolygon reads a GeoJSON Polygon or MultiPolygon from the request
+// body, unwrapping a Feature. It returns nil when the body is empty and the
+// bare geometry otherwise, ready for ST_GeomFromGeoJSON.
+func ReadPolygon(r *http.Request) (json.RawMessage, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, MaxPolygonBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, geo_exception.ErrInvalidPolygon
+		}
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var g geometry
+	if err := json.Unmarshal(body, &g); err != nil {
+		return nil, exceptions.ErrInvalidJSON.WithCause(err)
+	}
+	return parsePolygon(&g)
+}
+
+// parsePolygon validates the rings of a GeoJSON polygon geometry.
+func parsePolygon(g *geometry) (json.RawMessage, error) {
+	if g.Type == geo_entity.TypeFeature {
+		if g.Geometry == nil {
+			return nil, geo_exception.ErrInvalidPolygon
+		}
+		g = g.Geometry
+	}
+
+	var polygons [][][][]float64
+	switch g.Type {
+	case geo_entity.TypePolygon:
+		var polygon [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return nil, geo_exception.ErrInvalidPolygon
+		}
+		polygons = append(polygons, polygon)
+	case geo_entity.TypeMultiPolygon:
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, geo_exception.ErrInvalidPolygon
+		}
+	default:
+		return nil, geo_exception.ErrInvalidPolygon
+	}
+
+	positions := 0
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			return nil, geo_exception.ErrInvalidPolygon
+		}
+		for _, ring := range polygon {
+			if !validRing(ring) {
+				return nil, geo_exception.ErrInvalidPolygon
+			}
+			positions += len(ring)
+		}
+	}
+	if len(polygons) == 0 || positions > MaxPolygonPositions {
+		return nil, geo_exception.ErrInvalidPolygon
+	}
+
+	// re-encoding drops anything PostGIS shouldn't see, such as a crs member
+	return json.Marshal(&geometry{Type: g.Type, Coordinates: g.Coordinates})
+}
+
+// validRing checks a linear ring is closed, has at least four positions and
+// stays within WGS 84 bounds.
+func validRing(ring [][]float64) bool {
+	if len(ring) < 4 {
+		return false
+	}
+	for _, position := range ring {
+		if len(position) < 2 || position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+			return false
+		}
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	return first[0] == last[0] && first[1] == last[1]
+}
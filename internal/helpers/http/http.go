@@ -3,10 +3,16 @@ package http_helper
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	"github.com/danzBraham/beli-mang/internal/logger"
 )
 
 func DecodeJSON(r *http.Request, payload interface{}) error {
-	return json.NewDecoder(r.Body).Decode(payload)
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return exceptions.ErrInvalidJSON.WithCause(err)
+	}
+	return nil
 }
 
 func EncodeJSON(w http.ResponseWriter, status int, payload interface{}) error {
@@ -15,22 +21,63 @@ func EncodeJSON(w http.ResponseWriter, status int, payload interface{}) error {
 	return json.NewEncoder(w).Encode(payload)
 }
 
+// EncodeGeoJSON writes a GeoJSON document with its registered media type.
+func EncodeGeoJSON(w http.ResponseWriter, status int, payload interface{}) error {
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(payload)
+}
+
 type ResponseBody struct {
-	Error   string      `json:"error,omitempty"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
-func ResponseError(w http.ResponseWriter, status int, err string, message string) {
-	EncodeJSON(w, status, &ResponseBody{
-		Error:   err,
-		Message: message,
-	})
-}
-
 func ResponseSuccess(w http.ResponseWriter, status int, message string, data interface{}) {
 	EncodeJSON(w, status, &ResponseBody{
 		Message: message,
 		Data:    data,
 	})
 }
+
+// Problem is an RFC 7807 problem details body. Code is a stable,
+// machine-readable identifier clients can branch on.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail,omitempty"`
+	Instance  string                  `json:"instance,omitempty"`
+	Code      string                  `json:"code"`
+	RequestId string                  `json:"requestId,omitempty"`
+	Errors    []exceptions.FieldError `json:"errors,omitempty"`
+}
+
+// ResponseProblem translates err into an application/problem+json response.
+// Server errors are logged with their cause and answered with a generic
+// message.
+func ResponseProblem(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := exceptions.Translate(err)
+
+	l := logger.FromContext(r.Context())
+	if appErr.Status >= http.StatusInternalServerError {
+		l.Error("request failed", "code", appErr.Code, "error", err)
+	} else {
+		l.Debug("request rejected", "code", appErr.Code, "error", err)
+	}
+
+	problem := &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(appErr.Status),
+		Status:    appErr.Status,
+		Detail:    appErr.Message,
+		Instance:  r.URL.Path,
+		Code:      appErr.Code,
+		RequestId: w.Header().Get("X-Request-ID"),
+		Errors:    appErr.Errors,
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(appErr.Status)
+	json.NewEncoder(w).Encode(problem)
+}
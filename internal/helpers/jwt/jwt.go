@@ -2,22 +2,19 @@ package jwt_helper
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var key = []byte(os.Getenv("JWT_SECRET"))
-
 type CustomClaims struct {
 	UserId  string `json:"userId"`
 	IsAdmin bool   `json:"isAdmin"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(ttl time.Duration, userId string, isAdmin bool) (string, error) {
+func GenerateToken(secret string, ttl time.Duration, userId string, isAdmin bool) (string, error) {
 	now := time.Now()
 	expiry := now.Add(ttl)
 
@@ -32,7 +29,7 @@ func GenerateToken(ttl time.Duration, userId string, isAdmin bool) (string, erro
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(key)
+	return token.SignedString([]byte(secret))
 }
 
 type JWTPayload struct {
@@ -40,12 +37,12 @@ type JWTPayload struct {
 	IsAdmin bool
 }
 
-func VerifyToken(tokenString string) (*JWTPayload, error) {
+func VerifyToken(secret, tokenString string) (*JWTPayload, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Method.Alg())
 		}
-		return key, nil
+		return []byte(secret), nil
 	})
 	if token == nil {
 		return nil, auth_exception.ErrMissingToken
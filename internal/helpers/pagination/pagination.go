@@ -0,0 +1,110 @@
+package pagination_helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strconv"
+
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+	pagination_exception "github.com/danzBraham/beli-mang/internal/exceptions/pagination"
+)
+
+// ParsePage reads limit, offset and cursor from the query of a list read in
+// sort order. Sending a cursor switches the list to cursor mode.
+func ParsePage(query url.Values, sort string) (*pagination_entity.Page, error) {
+	page := &pagination_entity.Page{
+		Limit: pagination_entity.DefaultLimit,
+		Sort:  sort,
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > pagination_entity.MaxLimit {
+			return nil, pagination_exception.ErrInvalidLimit
+		}
+		page.Limit = value
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return nil, pagination_exception.ErrInvalidOffset
+		}
+		page.Offset = value
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		if query.Get("offset") != "" {
+			return nil, pagination_exception.ErrCursorWithOffset
+		}
+		value, err := DecodeCursor(cursor)
+		if err != nil || value.Sort != sort {
+			return nil, pagination_exception.ErrInvalidCursor
+		}
+		page.Cursor = value
+	}
+
+	return page, nil
+}
+
+func EncodeCursor(cursor pagination_entity.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(cursor string) (*pagination_entity.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, pagination_exception.ErrInvalidCursor
+	}
+	var value pagination_entity.Cursor
+	if err := json.Unmarshal(raw, &value); err != nil || value.Id == "" {
+		return nil, pagination_exception.ErrInvalidCursor
+	}
+	return &value, nil
+}
+
+// Paginate trims rows, read with one more than the limit to tell whether
+// another page follows, down to the page and returns the cursors of the next
+// and previous pages, nil when there is none. Rows read backward are put
+// back in list order. position returns the sort keys of a row.
+func Paginate[T any](rows []T, page *pagination_entity.Page, position func(row T) pagination_entity.Cursor) ([]T, *string, *string) {
+	hasMore := len(rows) > page.Limit
+	if hasMore {
+		rows = rows[:page.Limit]
+	}
+
+	backward := page.Cursor != nil && page.Cursor.Backward
+	if backward {
+		slices.Reverse(rows)
+	}
+	if len(rows) == 0 {
+		return rows, nil, nil
+	}
+
+	// reading forward the extra row means a next page, and any page but the
+	// first has a previous one; reading backward it is the other way round
+	hasNext, hasPrev := hasMore, page.Cursor != nil || page.Offset > 0
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	var next, prev *string
+	if hasNext {
+		cursor := position(rows[len(rows)-1])
+		cursor.Sort = page.Sort
+		encoded := EncodeCursor(cursor)
+		next = &encoded
+	}
+	if hasPrev {
+		cursor := position(rows[0])
+		cursor.Sort = page.Sort
+		cursor.Backward = true
+		encoded := EncodeCursor(cursor)
+		prev = &encoded
+	}
+
+	return rows, next, prev
+}
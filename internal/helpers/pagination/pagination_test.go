@@ -0,0 +1,236 @@
+package pagination_helper
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+	pagination_exception "github.com/danzBraham/beli-mang/internal/exceptions/pagination"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	distance := 0.0123
+	name := "Warung Sate"
+
+	cursors := []pagination_entity.Cursor{
+		{Sort: pagination_entity.SortCreatedAtDesc, Id: "01HX", CreatedAt: &createdAt},
+		{Sort: "distance", Backward: true, Id: "01HY", Distance: &distance},
+		{Sort: "name", Id: "01HZ", Name: &name},
+	}
+
+	for _, cursor := range cursors {
+		decoded, err := DecodeCursor(EncodeCursor(cursor))
+		if err != nil {
+			t.Fatalf("DecodeCursor(EncodeCursor(%+v)): %v", cursor, err)
+		}
+		if !reflect.DeepEqual(*decoded, cursor) {
+			t.Errorf("got %+v back, want %+v", *decoded, cursor)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsInvalidCursors(t *testing.T) {
+	cursors := map[string]string{
+		"not base64":  "%%%",
+		"not JSON":    encodeRaw("cursor"),
+		"JSON array":  encodeRaw(`["01HX"]`),
+		"without id":  encodeRaw(`{"s":"createdAt:desc"}`),
+		"wrong types": encodeRaw(`{"s":"createdAt:desc","i":"01HX","c":"yesterday"}`),
+	}
+
+	for name, cursor := range cursors {
+		if _, err := DecodeCursor(cursor); !errors.Is(err, pagination_exception.ErrInvalidCursor) {
+			t.Errorf("%s: got %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+// encodeRaw encodes raw the way cursors are, for hand made cursors.
+func encodeRaw(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func TestParsePage(t *testing.T) {
+	sort := pagination_entity.SortCreatedAtDesc
+	cursor := pagination_entity.Cursor{Sort: sort, Id: "01HX"}
+	otherSort := pagination_entity.Cursor{Sort: pagination_entity.SortCreatedAtAsc, Id: "01HX"}
+
+	cases := []struct {
+		name    string
+		query   string
+		want    *pagination_entity.Page
+		wantErr error
+	}{
+		{name: "defaults", query: "", want: &pagination_entity.Page{Limit: pagination_entity.DefaultLimit, Sort: sort}},
+		{name: "limit and offset", query: "limit=20&offset=40", want: &pagination_entity.Page{Limit: 20, Offset: 40, Sort: sort}},
+		{name: "largest limit", query: "limit=100", want: &pagination_entity.Page{Limit: 100, Sort: sort}},
+		{name: "cursor", query: "cursor=" + EncodeCursor(cursor), want: &pagination_entity.Page{Limit: pagination_entity.DefaultLimit, Sort: sort, Cursor: &cursor}},
+		{name: "empty cursor", query: "cursor=", want: &pagination_entity.Page{Limit: pagination_entity.DefaultLimit, Sort: sort}},
+		{name: "zero limit", query: "limit=0", wantErr: pagination_exception.ErrInvalidLimit},
+		{name: "limit over the maximum", query: "limit=101", wantErr: pagination_exception.ErrInvalidLimit},
+		{name: "limit not a number", query: "limit=ten", wantErr: pagination_exception.ErrInvalidLimit},
+		{name: "negative offset", query: "offset=-1", wantErr: pagination_exception.ErrInvalidOffset},
+		{name: "offset not a number", query: "offset=1.5", wantErr: pagination_exception.ErrInvalidOffset},
+		{name: "cursor and offset", query: "offset=0&cursor=" + EncodeCursor(cursor), wantErr: pagination_exception.ErrCursorWithOffset},
+		{name: "cursor of another sort", query: "cursor=" + EncodeCursor(otherSort), wantErr: pagination_exception.ErrInvalidCursor},
+		{name: "garbage cursor", query: "cursor=abc", wantErr: pagination_exception.ErrInvalidCursor},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, err := url.ParseQuery(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			page, err := ParsePage(query, sort)
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("got error %v, want %v", err, c.wantErr)
+			}
+			if !reflect.DeepEqual(page, c.want) {
+				t.Errorf("got %+v, want %+v", page, c.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	const sort = "id"
+	// rows are ids, a row's position is its id
+	position := func(row int) pagination_entity.Cursor {
+		return pagination_entity.Cursor{Id: strconv.Itoa(row)}
+	}
+	after := func(id int) *pagination_entity.Cursor {
+		return &pagination_entity.Cursor{Sort: sort, Id: strconv.Itoa(id)}
+	}
+	before := func(id int) *pagination_entity.Cursor {
+		return &pagination_entity.Cursor{Sort: sort, Backward: true, Id: strconv.Itoa(id)}
+	}
+
+	cases := []struct {
+		name string
+		page pagination_entity.Page
+		// rows as the query returns them, limit + 1 at most
+		rows     []int
+		want     []int
+		wantNext *pagination_entity.Cursor
+		wantPrev *pagination_entity.Cursor
+	}{
+		{
+			name:     "first page with more",
+			page:     pagination_entity.Page{Limit: 3},
+			rows:     []int{1, 2, 3, 4},
+			want:     []int{1, 2, 3},
+			wantNext: after(3),
+		},
+		{
+			name: "only page",
+			page: pagination_entity.Page{Limit: 3},
+			rows: []int{1, 2},
+			want: []int{1, 2},
+		},
+		{
+			name: "empty list",
+			page: pagination_entity.Page{Limit: 3},
+			rows: []int{},
+			want: []int{},
+		},
+		{
+			name:     "offset page with more",
+			page:     pagination_entity.Page{Limit: 3, Offset: 3},
+			rows:     []int{4, 5, 6, 7},
+			want:     []int{4, 5, 6},
+			wantNext: after(6),
+			wantPrev: before(4),
+		},
+		{
+			name:     "last offset page",
+			page:     pagination_entity.Page{Limit: 3, Offset: 6},
+			rows:     []int{7},
+			want:     []int{7},
+			wantPrev: before(7),
+		},
+		{
+			name: "offset past the end",
+			page: pagination_entity.Page{Limit: 3, Offset: 9},
+			rows: []int{},
+			want: []int{},
+		},
+		{
+			name:     "forward with more",
+			page:     pagination_entity.Page{Limit: 3, Cursor: after(3)},
+			rows:     []int{4, 5, 6, 7},
+			want:     []int{4, 5, 6},
+			wantNext: after(6),
+			wantPrev: before(4),
+		},
+		{
+			name:     "forward to the last page",
+			page:     pagination_entity.Page{Limit: 3, Cursor: after(6)},
+			rows:     []int{7, 8, 9},
+			want:     []int{7, 8, 9},
+			wantPrev: before(7),
+		},
+		{
+			name: "forward past the end",
+			page: pagination_entity.Page{Limit: 3, Cursor: after(9)},
+			rows: []int{},
+			want: []int{},
+		},
+		{
+			// read backward the rows arrive nearest the cursor first
+			name:     "backward with more",
+			page:     pagination_entity.Page{Limit: 3, Cursor: before(7)},
+			rows:     []int{6, 5, 4, 3},
+			want:     []int{4, 5, 6},
+			wantNext: after(6),
+			wantPrev: before(4),
+		},
+		{
+			name:     "backward to the first page",
+			page:     pagination_entity.Page{Limit: 3, Cursor: before(4)},
+			rows:     []int{3, 2, 1},
+			want:     []int{1, 2, 3},
+			wantNext: after(3),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			page := c.page
+			page.Sort = sort
+
+			rows, next, prev := Paginate(slices.Clone(c.rows), &page, position)
+			if !reflect.DeepEqual(rows, c.want) {
+				t.Errorf("got rows %v, want %v", rows, c.want)
+			}
+			checkCursor(t, "next", next, c.wantNext)
+			checkCursor(t, "prev", prev, c.wantPrev)
+		})
+	}
+}
+
+func checkCursor(t *testing.T, name string, got *string, want *pagination_entity.Cursor) {
+	t.Helper()
+	if got == nil || want == nil {
+		if got != nil || want != nil {
+			t.Errorf("%s cursor is %v, want %+v", name, got, want)
+		}
+		return
+	}
+
+	decoded, err := DecodeCursor(*got)
+	if err != nil {
+		t.Fatalf("%s cursor: %v", name, err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("%s cursor is %+v, want %+v", name, decoded, want)
+	}
+}
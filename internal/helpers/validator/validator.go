@@ -1,38 +1,148 @@
 package validator_helper
 
 import (
-	"fmt"
 	"net/url"
 	"path"
+	"reflect"
 	"strings"
 
 	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
 )
 
-var validate = validator.New(validator.WithRequiredStructEnabled())
+var (
+	validate = validator.New(validator.WithRequiredStructEnabled())
+	uni      = ut.New(en.New(), en.New(), id.New())
+)
 
-func formatValidatorErrors(err error) string {
-	var sb strings.Builder
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldError := range validationErrors {
-			sb.WriteString(fmt.Sprintf("Field '%s' failed on the '%s' tag;", fieldError.Field(), fieldError.Tag()))
-		}
-		return sb.String()
-	}
-	return err.Error()
+type customTranslation struct {
+	tag     string
+	english string
+	bahasa  string
+}
+
+var customTranslations = []customTranslation{
+	{
+		tag:     "imageurl",
+		english: "{0} must be a valid http(s) URL to a .jpg, .jpeg or .png image",
+		bahasa:  "{0} harus berupa URL http(s) yang valid ke gambar .jpg, .jpeg atau .png",
+	},
+	{
+		tag:     "onestartingpoint",
+		english: "{0} must contain exactly one starting point",
+		bahasa:  "{0} harus memiliki tepat satu titik awal",
+	},
 }
 
 func InitCustomValidation() {
+	validate.RegisterTagNameFunc(jsonTagName)
+
 	validate.RegisterValidation("imageurl", validateImageURL)
 	validate.RegisterValidation("onestartingpoint", validateOneStartingPoint)
+
+	enTrans, _ := uni.GetTranslator("en")
+	idTrans, _ := uni.GetTranslator("id")
+	must(en_translations.RegisterDefaultTranslations(validate, enTrans))
+	must(id_translations.RegisterDefaultTranslations(validate, idTrans))
+
+	for _, t := range customTranslations {
+		must(registerTranslation(enTrans, t.tag, t.english))
+		must(registerTranslation(idTrans, t.tag, t.bahasa))
+	}
+}
+
+// ValidatePayload validates payload and reports every failing field, with
+// messages in the best language matching acceptLanguage (English by default).
+func ValidatePayload(payload interface{}, acceptLanguage string) error {
+	err := validate.Struct(payload)
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return exceptions.ErrValidation.WithCause(err)
+	}
+
+	trans, _ := uni.FindTranslator(parseAcceptLanguage(acceptLanguage)...)
+
+	fieldErrors := make([]exceptions.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fieldErrors = append(fieldErrors, exceptions.FieldError{
+			Field:   fieldPath(fieldError.Namespace()),
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: fieldError.Translate(trans),
+		})
+	}
+
+	return exceptions.ErrValidation.WithErrors(fieldErrors).WithCause(err)
+}
+
+func jsonTagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath drops the root struct name from a namespace such as
+// "UserEstimateRequest.orders[1].items[0].quantity".
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
 }
 
-func ValidatePayload(payload interface{}) error {
-	if err := validate.Struct(payload); err != nil {
-		return fmt.Errorf(formatValidatorErrors(err))
+// parseAcceptLanguage returns the language tags of an Accept-Language header
+// in order of preference, both as sent ("id-ID") and as base language ("id").
+func parseAcceptLanguage(header string) []string {
+	locales := []string{}
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		tag = strings.ReplaceAll(tag, "-", "_")
+		locales = append(locales, tag)
+		if base, _, found := strings.Cut(tag, "_"); found {
+			locales = append(locales, base)
+		}
+	}
+	return locales
+}
+
+func registerTranslation(trans ut.Translator, tag, text string) error {
+	return validate.RegisterTranslation(tag, trans,
+		func(ut ut.Translator) error {
+			return ut.Add(tag, text, true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			message, err := ut.T(tag, fe.Field())
+			if err != nil {
+				return fe.Error()
+			}
+			return message
+		},
+	)
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
 	}
-	return nil
 }
 
 func validateImageURL(fl validator.FieldLevel) bool {
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"net/http"
+
+	address_entity "github.com/danzBraham/beli-mang/internal/entities/address"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
+	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
+	"github.com/danzBraham/beli-mang/internal/http/middlewares"
+	"github.com/danzBraham/beli-mang/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type AddressController struct {
+	Service services.AddressService
+}
+
+func NewAddressController(service services.AddressService) *AddressController {
+	return &AddressController{Service: service}
+}
+
+func (c *AddressController) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/", c.handleAddAddress)
+	r.Get("/", c.handleGetAddresses)
+	r.Get("/{addressId}", c.handleGetAddress)
+	r.Put("/{addressId}", c.handleUpdateAddress)
+	r.Delete("/{addressId}", c.handleDeleteAddress)
+
+	return r
+}
+
+func (c *AddressController) handleAddAddress(w http.ResponseWriter, r *http.Request) {
+	userId, ok := addressOwner(w, r)
+	if !ok {
+		return
+	}
+
+	payload := &address_entity.AddressRequest{}
+
+	err := http_helper.DecodeJSON(r, payload)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	err = validator_helper.ValidatePayload(payload, r.Header.Get("Accept-Language"))
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	addressResponse, err := c.Service.CreateAddress(r.Context(), userId, payload)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, http.StatusCreated, addressResponse)
+}
+
+func (c *AddressController) handleGetAddresses(w http.ResponseWriter, r *http.Request) {
+	userId, ok := addressOwner(w, r)
+	if !ok {
+		return
+	}
+
+	addressesResponse, err := c.Service.GetAddresses(r.Context(), userId)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, http.StatusOK, addressesResponse)
+}
+
+func (c *AddressController) handleGetAddress(w http.ResponseWriter, r *http.Request) {
+	userId, ok := addressOwner(w, r)
+	if !ok {
+		return
+	}
+
+	address, err := c.Service.GetAddress(r.Context(), userId, chi.URLParam(r, "addressId"))
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, http.StatusOK, address)
+}
+
+func (c *AddressController) handleUpdateAddress(w http.ResponseWriter, r *http.Request) {
+	userId, ok := addressOwner(w, r)
+	if !ok {
+		return
+	}
+
+	payload := &address_entity.AddressRequest{}
+
+	err := http_helper.DecodeJSON(r, payload)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	err = validator_helper.ValidatePayload(payload, r.Header.Get("Accept-Language"))
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	address, err := c.Service.UpdateAddress(r.Context(), userId, chi.URLParam(r, "addressId"), payload)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, http.StatusOK, address)
+}
+
+func (c *AddressController) handleDeleteAddress(w http.ResponseWriter, r *http.Request) {
+	userId, ok := addressOwner(w, r)
+	if !ok {
+		return
+	}
+
+	err := c.Service.DeleteAddress(r.Context(), userId, chi.URLParam(r, "addressId"))
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addressOwner returns the id of the user calling, answering the request
+// itself when the caller is an admin or the claims are unusable.
+func addressOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return "", false
+	}
+	if isAdmin {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotUser)
+		return "", false
+	}
+
+	userId, ok := r.Context().Value(middlewares.ContextUserIdKey).(string)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return "", false
+	}
+
+	return userId, true
+}
@@ -1,12 +1,10 @@
 package controllers
 
 import (
-	"errors"
 	"net/http"
 	"time"
 
 	user_entity "github.com/danzBraham/beli-mang/internal/entities/user"
-	user_exception "github.com/danzBraham/beli-mang/internal/exceptions/user"
 	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
 	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
 	"github.com/danzBraham/beli-mang/internal/services"
@@ -35,27 +33,19 @@ func (c *AdminController) handleRegisterAdminUser(w http.ResponseWriter, r *http
 
 	err := http_helper.DecodeJSON(r, payload)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Failed to decode JSON")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
-	err = validator_helper.ValidatePayload(payload)
+	err = validator_helper.ValidatePayload(payload, r.Header.Get("Accept-Language"))
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Request doesn't pass validation")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
 	userRepsonse, err := c.Service.RegisterAdminUser(r.Context(), payload)
-	if errors.Is(err, user_exception.ErrUsernameAlreadyExists) {
-		http_helper.ResponseError(w, http.StatusConflict, "Conflict error", err.Error())
-		return
-	}
-	if errors.Is(err, user_exception.ErrAdminEmailAlreadyExists) {
-		http_helper.ResponseError(w, http.StatusConflict, "Conflict error", err.Error())
-		return
-	}
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
@@ -74,27 +64,19 @@ func (c *AdminController) handleLoginAdminUser(w http.ResponseWriter, r *http.Re
 
 	err := http_helper.DecodeJSON(r, payload)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Failed to decode JSON")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
-	err = validator_helper.ValidatePayload(payload)
+	err = validator_helper.ValidatePayload(payload, r.Header.Get("Accept-Language"))
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Request doesn't pass validation")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
 	userRepsonse, err := c.Service.LoginAdminUser(r.Context(), payload)
-	if errors.Is(err, user_exception.ErrUserNotFound) {
-		http_helper.ResponseError(w, http.StatusNotFound, "Not found error", err.Error())
-		return
-	}
-	if errors.Is(err, user_exception.ErrInvalidPassword) {
-		http_helper.ResponseError(w, http.StatusBadRequest, "Bad request error", err.Error())
-		return
-	}
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
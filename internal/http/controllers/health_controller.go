@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+
+	health_entity "github.com/danzBraham/beli-mang/internal/entities/health"
+	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	"github.com/danzBraham/beli-mang/internal/services"
+)
+
+type HealthController struct {
+	Service services.HealthService
+}
+
+func NewHealthController(service services.HealthService) *HealthController {
+	return &HealthController{Service: service}
+}
+
+func (c *HealthController) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	http_helper.EncodeJSON(w, http.StatusOK, c.Service.Liveness(r.Context()))
+}
+
+func (c *HealthController) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	readiness := c.Service.Readiness(r.Context())
+
+	status := http.StatusOK
+	if readiness.Status != health_entity.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http_helper.EncodeJSON(w, status, readiness)
+}
@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bulk_entity "github.com/danzBraham/beli-mang/internal/entities/bulk"
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
+	bulk_exception "github.com/danzBraham/beli-mang/internal/exceptions/bulk"
+	bulk_helper "github.com/danzBraham/beli-mang/internal/helpers/bulk"
+	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	"github.com/danzBraham/beli-mang/internal/http/middlewares"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxImportBytes bounds the size of an uploaded import file.
+const maxImportBytes = 10 << 20
+
+var (
+	merchantColumns = []string{"name", "merchantCategory", "imageUrl", "lat", "long"}
+	itemColumns     = []string{"merchantId", "name", "productCategory", "price", "imageUrl"}
+)
+
+type ImportController struct {
+	Service services.ImportService
+}
+
+func NewImportController(service services.ImportService) *ImportController {
+	return &ImportController{Service: service}
+}
+
+func (c *ImportController) HandleImportMerchants(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+	if !isAdmin {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
+		return
+	}
+
+	userId, ok := r.Context().Value(middlewares.ContextUserIdKey).(string)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+
+	format, err := bulk_helper.DetectFormat(r)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	opts, err := importOptions(r)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	rows, err := bulk_helper.Decode(body, format, merchantColumns, merchantFromRecord)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	report, err := c.Service.ImportMerchants(r.Context(), userId, rows, opts)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, importStatus(report), report)
+}
+
+func (c *ImportController) HandleImportItems(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+	if !isAdmin {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
+		return
+	}
+
+	format, err := bulk_helper.DetectFormat(r)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	opts, err := importOptions(r)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	// rows imported under /merchants/{merchantId}/items belong to that merchant
+	merchantId := chi.URLParam(r, "merchantId")
+	columns := itemColumns
+	if merchantId != "" {
+		columns = itemColumns[1:]
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	rows, err := bulk_helper.Decode(body, format, columns, itemFromRecord)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+	if merchantId != "" {
+		for _, row := range rows {
+			if row.Payload != nil {
+				row.Payload.MerchantId = merchantId
+			}
+		}
+	}
+
+	report, err := c.Service.ImportItems(r.Context(), rows, opts)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, importStatus(report), report)
+}
+
+func (c *ImportController) HandleExportMerchants(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+	if !isAdmin {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
+		return
+	}
+
+	format, err := bulk_helper.DetectFormat(r)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	header := append([]string{"merchantId"}, merchantColumns...)
+	header = append(header, "address", "preparationTimeInMinutes", "createdAt")
+
+	c.export(w, r, format, "merchants", header, func(writer *bulk_helper.Writer) error {
+		return c.Service.ExportMerchants(r.Context(), func(merchant *merchant_entity.Merchant) error {
+			exportMerchant := &merchant_entity.ExportMerchant{
+				GetMerchant: merchant_entity.GetMerchant{
+					Id:        merchant.Id,
+					Name:      merchant.Name,
+					Category:  merchant.Category,
+					ImageURL:  merchant.ImageURL,
+					Location:  merchant.Location,
+					Address:   merchant.Address,
+					CreatedAt: merchant.CreatedAt,
+				},
+				PreparationMinutes: merchant.PreparationMinutes,
+			}
+			address := ""
+			if merchant.Address != nil {
+				address = *merchant.Address
+			}
+			preparationMinutes := ""
+			if merchant.PreparationMinutes != nil {
+				preparationMinutes = strconv.Itoa(*merchant.PreparationMinutes)
+			}
+			return writer.Write(exportMerchant, []string{
+				merchant.Id,
+				merchant.Name,
+				merchant.Category,
+				merchant.ImageURL,
+				strconv.FormatFloat(merchant.Location.Lat, 'f', -1, 64),
+				strconv.FormatFloat(merchant.Location.Long, 'f', -1, 64),
+				address,
+				preparationMinutes,
+				merchant.CreatedAt,
+			})
+		})
+	})
+}
+
+func (c *ImportController) HandleExportItems(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+	if !isAdmin {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
+		return
+	}
+
+	format, err := bulk_helper.DetectFormat(r)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	merchantId := chi.URLParam(r, "merchantId")
+	header := append([]string{"itemId"}, itemColumns...)
+	header = append(header, "createdAt")
+
+	c.export(w, r, format, "items", header, func(writer *bulk_helper.Writer) error {
+		return c.Service.ExportItems(r.Context(), merchantId, func(item *item_entity.Item) error {
+			exportItem := &item_entity.ExportItem{
+				Id:         item.Id,
+				MerchantId: item.MerchantId,
+				Name:       item.Name,
+				Category:   item.Category,
+				Price:      item.Price,
+				ImageURL:   item.ImageURL,
+				CreatedAt:  item.CreatedAt,
+			}
+			return writer.Write(exportItem, []string{
+				item.Id,
+				item.MerchantId,
+				item.Name,
+				item.Category,
+				strconv.Itoa(item.Price),
+				item.ImageURL,
+				item.CreatedAt,
+			})
+		})
+	})
+}
+
+// export streams rows as an attachment. The status is sent with the first
+// row, so errors after that can only be logged and end the download early.
+func (c *ImportController) export(w http.ResponseWriter, r *http.Request, format, name string, header []string, fn func(writer *bulk_helper.Writer) error) {
+	rw := &lazyHeaderWriter{ResponseWriter: w, setHeaders: func(h http.Header) {
+		h.Set("Content-Type", bulk_helper.ContentType(format))
+		h.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	}}
+
+	writer, err := bulk_helper.NewWriter(rw, format, header)
+	if err == nil {
+		err = fn(writer)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		rw.commit()
+		return
+	}
+
+	if !rw.written {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+	logger.FromContext(r.Context()).Error("export interrupted", "export", name, "error", err)
+}
+
+// lazyHeaderWriter delays the export headers until the first byte, so a
+// failure before any row is written still becomes a problem response.
+type lazyHeaderWriter struct {
+	http.ResponseWriter
+	setHeaders func(h http.Header)
+	written    bool
+}
+
+func (w *lazyHeaderWriter) Write(p []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *lazyHeaderWriter) commit() {
+	if !w.written {
+		w.written = true
+		w.setHeaders(w.Header())
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func importOptions(r *http.Request) (*bulk_entity.ImportOptions, error) {
+	query := r.URL.Query()
+
+	opts := &bulk_entity.ImportOptions{
+		Mode:     bulk_entity.ModeAtomic,
+		Language: r.Header.Get("Accept-Language"),
+	}
+	if mode := query.Get("mode"); mode != "" {
+		opts.Mode = mode
+	}
+	if dryRun := query.Get("dryRun"); dryRun != "" {
+		value, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return nil, bulk_exception.ErrInvalidDryRun
+		}
+		opts.DryRun = value
+	}
+
+	return opts, nil
+}
+
+// importStatus is 201 when rows were written and 422 when an atomic import
+// was refused, the report is returned either way.
+func importStatus(report *bulk_entity.ImportReport) int {
+	switch {
+	case report.Imported > 0:
+		return http.StatusCreated
+	case report.Mode == bulk_entity.ModeAtomic && report.Failed > 0:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusOK
+	}
+}
+
+// merchantFromRecord reads a merchant row. lat and long may be left empty
+// for a row with an address, which is then geocoded.
+func merchantFromRecord(record map[string]string) (*merchant_entity.AddMerchantRequest, error) {
+	var location *merchant_entity.Location
+	if record["lat"] != "" || record["long"] != "" {
+		lat, err := strconv.ParseFloat(record["lat"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("lat must be a number, got %q", record["lat"])
+		}
+		long, err := strconv.ParseFloat(record["long"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("long must be a number, got %q", record["long"])
+		}
+		location = &merchant_entity.Location{Lat: lat, Long: long}
+	}
+
+	// preparation time is an optional column
+	var preparationMinutes *int
+	if value := record["preparationTimeInMinutes"]; value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("preparationTimeInMinutes must be a whole number, got %q", value)
+		}
+		preparationMinutes = &minutes
+	}
+
+	return &merchant_entity.AddMerchantRequest{
+		Name:               record["name"],
+		Category:           record["merchantCategory"],
+		ImageURL:           record["imageUrl"],
+		Location:           location,
+		Address:            record["address"],
+		PreparationMinutes: preparationMinutes,
+	}, nil
+}
+
+func itemFromRecord(record map[string]string) (*item_entity.ImportItemRequest, error) {
+	price, err := strconv.Atoi(record["price"])
+	if err != nil {
+		return nil, fmt.Errorf("price must be a whole number, got %q", record["price"])
+	}
+
+	return &item_entity.ImportItemRequest{
+		MerchantId: record["merchantId"],
+		Name:       record["name"],
+		Category:   record["productCategory"],
+		Price:      price,
+		ImageURL:   record["imageUrl"],
+	}, nil
+}
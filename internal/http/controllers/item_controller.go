@@ -1,13 +1,13 @@
 package controllers
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
 
 	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
-	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
 	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	pagination_helper "github.com/danzBraham/beli-mang/internal/helpers/pagination"
 	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
 	"github.com/danzBraham/beli-mang/internal/http/middlewares"
 	"github.com/danzBraham/beli-mang/internal/services"
@@ -25,7 +25,6 @@ func NewItemController(service services.ItemService) *ItemController {
 func (c *ItemController) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Use(middlewares.Authenticate)
 	r.Post("/", c.handleAddItem)
 	r.Get("/", c.handleGetItems)
 
@@ -35,11 +34,11 @@ func (c *ItemController) Routes() chi.Router {
 func (c *ItemController) handleAddItem(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if !isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not admin")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
 		return
 	}
 
@@ -48,23 +47,19 @@ func (c *ItemController) handleAddItem(w http.ResponseWriter, r *http.Request) {
 
 	err := http_helper.DecodeJSON(r, payload)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Failed to decode JSON")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
-	err = validator_helper.ValidatePayload(payload)
+	err = validator_helper.ValidatePayload(payload, r.Header.Get("Accept-Language"))
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Request doesn't pass validation")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
 	itemResponse, err := c.Service.CreateItem(r.Context(), merchantId, payload)
-	if errors.Is(err, merchant_exception.ErrMerchantIdNotFound) {
-		http_helper.ResponseError(w, http.StatusNotFound, "Not found error", err.Error())
-		return
-	}
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
@@ -74,11 +69,11 @@ func (c *ItemController) handleAddItem(w http.ResponseWriter, r *http.Request) {
 func (c *ItemController) handleGetItems(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if !isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not admin")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
 		return
 	}
 
@@ -87,28 +82,25 @@ func (c *ItemController) handleGetItems(w http.ResponseWriter, r *http.Request)
 
 	params := &item_entity.ItemQueryParams{
 		Id:        query.Get("itemId"),
-		Limit:     5,
-		Offset:    0,
 		Name:      query.Get("name"),
 		Category:  query.Get("productCategory"),
 		CreatedAt: query.Get("createdAt"),
 	}
 
-	if limit := query.Get("limit"); limit != "" {
-		params.Limit, _ = strconv.Atoi(limit)
+	sort := pagination_entity.SortCreatedAtDesc
+	if params.CreatedAt == "asc" {
+		sort = pagination_entity.SortCreatedAtAsc
 	}
-
-	if offset := query.Get("offset"); offset != "" {
-		params.Offset, _ = strconv.Atoi(offset)
+	page, err := pagination_helper.ParsePage(query, sort)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
 	}
+	params.Page = page
 
 	itemsResponse, err := c.Service.GetItems(r.Context(), merchantId, params)
-	if errors.Is(err, merchant_exception.ErrMerchantIdNotFound) {
-		http_helper.ResponseError(w, http.StatusNotFound, "Not found error", err.Error())
-		return
-	}
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
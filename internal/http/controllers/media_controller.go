@@ -1,84 +1,90 @@
 package controllers
 
 import (
-	"context"
+	"fmt"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/danzBraham/beli-mang/internal/config"
 	media_entity "github.com/danzBraham/beli-mang/internal/entities/media"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
+	media_exception "github.com/danzBraham/beli-mang/internal/exceptions/media"
 	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	s3_helper "github.com/danzBraham/beli-mang/internal/helpers/s3"
 	"github.com/danzBraham/beli-mang/internal/http/middlewares"
+	"github.com/danzBraham/beli-mang/internal/metrics"
 	"github.com/google/uuid"
 )
 
-type MediaController struct{}
+type MediaController struct {
+	Config config.AWSConfig
+}
 
-func NewMediaController() *MediaController {
-	return &MediaController{}
+func NewMediaController(cfg config.AWSConfig) *MediaController {
+	return &MediaController{Config: cfg}
 }
 
 func (c *MediaController) HandleUploadImage(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if !isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not admin")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
 		return
 	}
 
 	err := r.ParseMultipartForm(media_entity.MaxUploadSize)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Unable to parse form")
+		http_helper.ResponseProblem(w, r, fmt.Errorf("%w: %w", media_exception.ErrInvalidForm, err))
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Unable to get file from form")
+		http_helper.ResponseProblem(w, r, fmt.Errorf("%w: %w", media_exception.ErrMissingFile, err))
 		return
 	}
 	defer file.Close()
 
 	fileExt := strings.ToLower(filepath.Ext(header.Filename))
 	if fileExt != ".jpg" && fileExt != ".jpeg" {
-		http_helper.ResponseError(w, http.StatusBadRequest, "Bad request error", "File must be in .jpg or .jpeg format")
+		http_helper.ResponseProblem(w, r, media_exception.ErrInvalidFileType)
 		return
 	}
 
 	if header.Size < media_entity.MinUploadSize || header.Size > media_entity.MaxUploadSize {
-		http_helper.ResponseError(w, http.StatusBadRequest, "Bad request error", "File size must be between 10KB and 2MB")
+		http_helper.ResponseProblem(w, r, media_exception.ErrInvalidFileSize)
 		return
 	}
 
 	filename := uuid.New().String() + fileExt
 
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+	client, err := s3_helper.NewClient(r.Context(), c.Config)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, "Bad request error", err.Error())
+		metrics.Uploads.WithLabelValues("failure").Inc()
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
-	client := s3.NewFromConfig(cfg)
-
 	uploader := manager.NewUploader(client)
-	result, err := uploader.Upload(context.TODO(), &s3.PutObjectInput{
-		Bucket: aws.String(os.Getenv("AWS_S3_BUCKET_NAME")),
+	result, err := uploader.Upload(r.Context(), &s3.PutObjectInput{
+		Bucket: aws.String(c.Config.BucketName),
 		Key:    aws.String(filename),
 		Body:   file,
 		ACL:    "public-read",
 	})
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, "Bad request error", err.Error())
+		metrics.Uploads.WithLabelValues("failure").Inc()
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
+	metrics.Uploads.WithLabelValues("success").Inc()
 
 	http_helper.ResponseSuccess(w, http.StatusOK, "File uploaded sucessfully", &media_entity.UploadImageResponse{
 		ImageURL: result.Location,
@@ -3,9 +3,17 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
 	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
+	geo_exception "github.com/danzBraham/beli-mang/internal/exceptions/geo"
+	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
+	geo_helper "github.com/danzBraham/beli-mang/internal/helpers/geo"
 	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	pagination_helper "github.com/danzBraham/beli-mang/internal/helpers/pagination"
 	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
 	"github.com/danzBraham/beli-mang/internal/http/middlewares"
 	"github.com/danzBraham/beli-mang/internal/services"
@@ -23,7 +31,6 @@ func NewMerchantController(service services.MerchantService) *MerchantController
 func (c *MerchantController) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Use(middlewares.Authenticate)
 	r.Post("/", c.handleAddMerchant)
 	r.Get("/", c.handleGetMerchants)
 
@@ -33,36 +40,36 @@ func (c *MerchantController) Routes() chi.Router {
 func (c *MerchantController) handleAddMerchant(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if !isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not admin")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
 		return
 	}
 
 	userId, ok := r.Context().Value(middlewares.ContextUserIdKey).(string)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	paylaod := &merchant_entity.AddMerchantRequest{}
 
 	err := http_helper.DecodeJSON(r, paylaod)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Failed to decode JSON")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
-	err = validator_helper.ValidatePayload(paylaod)
+	err = validator_helper.ValidatePayload(paylaod, r.Header.Get("Accept-Language"))
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Request doesn't pass validation")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
 	merchantResponse, err := c.Service.CreateMerchant(r.Context(), userId, paylaod)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
@@ -72,11 +79,11 @@ func (c *MerchantController) handleAddMerchant(w http.ResponseWriter, r *http.Re
 func (c *MerchantController) handleGetMerchants(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if !isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not admin")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
 		return
 	}
 
@@ -84,26 +91,117 @@ func (c *MerchantController) handleGetMerchants(w http.ResponseWriter, r *http.R
 
 	params := &merchant_entity.MerchantQueryParams{
 		Id:        query.Get("merchantId"),
-		Limit:     5,
-		Offset:    0,
 		Name:      query.Get("name"),
 		Category:  query.Get("merchantCategory"),
 		CreatedAt: query.Get("createdAt"),
 	}
 
-	if limit := query.Get("limit"); limit != "" {
-		params.Limit, _ = strconv.Atoi(limit)
+	sort := pagination_entity.SortCreatedAtDesc
+	if params.CreatedAt == "asc" {
+		sort = pagination_entity.SortCreatedAtAsc
 	}
-
-	if offset := query.Get("offset"); offset != "" {
-		params.Offset, _ = strconv.Atoi(offset)
+	page, err := pagination_helper.ParsePage(query, sort)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
 	}
+	params.Page = page
 
 	merchantsResponse, err := c.Service.GetMerchants(r.Context(), params)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
 	http_helper.EncodeJSON(w, http.StatusOK, &merchantsResponse)
 }
+
+// HandleGetMerchantsWithin lists the merchants inside a bbox, a GeoJSON
+// polygon body, or both. A zoom below MaxClusterZoom clusters them.
+func (c *MerchantController) HandleGetMerchantsWithin(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool); !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+
+	query := r.URL.Query()
+	params := &merchant_entity.MerchantWithinQueryParams{
+		Name:     query.Get("name"),
+		Category: query.Get("merchantCategory"),
+		Limit:    merchant_entity.DefaultWithinLimit,
+	}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		value, err := parseBBox(bbox)
+		if err != nil {
+			http_helper.ResponseProblem(w, r, err)
+			return
+		}
+		params.BBox = value
+	}
+
+	polygon, err := geo_helper.ReadPolygon(r)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+	params.Polygon = polygon
+
+	if params.BBox == nil && params.Polygon == nil {
+		http_helper.ResponseProblem(w, r, geo_exception.ErrMissingArea)
+		return
+	}
+
+	if zoom := query.Get("zoom"); zoom != "" {
+		value, err := strconv.Atoi(zoom)
+		if err != nil || value < merchant_entity.MinZoom || value > merchant_entity.MaxZoom {
+			http_helper.ResponseProblem(w, r, geo_exception.ErrInvalidZoom)
+			return
+		}
+		params.Zoom = &value
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > merchant_entity.MaxWithinLimit {
+			http_helper.ResponseProblem(w, r, merchant_exception.ErrInvalidWithinLimit)
+			return
+		}
+		params.Limit = value
+	}
+
+	merchantsResponse, err := c.Service.GetMerchantsWithin(r.Context(), params)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	if query.Get("format") == "geojson" || strings.Contains(r.Header.Get("Accept"), "application/geo+json") {
+		http_helper.EncodeGeoJSON(w, http.StatusOK, merchantsWithinFeatures(merchantsResponse))
+		return
+	}
+
+	http_helper.EncodeJSON(w, http.StatusOK, &merchantsResponse)
+}
+
+// merchantsWithinFeatures renders merchants as point features carrying their
+// details and clusters as point features carrying their size.
+func merchantsWithinFeatures(response *merchant_entity.GetMerchantsWithinResponse) *geo_entity.FeatureCollection {
+	features := make([]*geo_entity.Feature, 0, len(response.Data))
+	for _, within := range response.Data {
+		feature := &geo_entity.Feature{
+			Type:     geo_entity.TypeFeature,
+			Geometry: geo_entity.NewPoint(within.Location.Lat, within.Location.Long),
+			Properties: &merchant_entity.ClusterProperties{
+				Cluster:    true,
+				PointCount: within.Count,
+			},
+		}
+		if within.Merchant != nil {
+			feature.Id = within.Merchant.Id
+			feature.Properties = within.Merchant
+		}
+		features = append(features, feature)
+	}
+	return geo_entity.NewFeatureCollection(features)
+}
@@ -1,15 +1,17 @@
 package controllers
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
 	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
-	item_exception "github.com/danzBraham/beli-mang/internal/exceptions/item"
-	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
 	purchase_exception "github.com/danzBraham/beli-mang/internal/exceptions/purchase"
 	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	pagination_helper "github.com/danzBraham/beli-mang/internal/helpers/pagination"
 	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
 	"github.com/danzBraham/beli-mang/internal/http/middlewares"
 	"github.com/danzBraham/beli-mang/internal/services"
@@ -27,22 +29,22 @@ func NewPurchaseController(service services.PurchaseService) *PurchaseController
 func (c *PurchaseController) HandleGetMerchantsNearby(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not a user")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotUser)
 		return
 	}
 
 	lat, err := strconv.ParseFloat(chi.URLParam(r, "lat"), 64)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, "Bad request error", "lat is not valid")
+		http_helper.ResponseProblem(w, r, purchase_exception.ErrInvalidLocation)
 		return
 	}
 	long, err := strconv.ParseFloat(chi.URLParam(r, "long"), 64)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, purchase_exception.ErrInvalidLocation)
 		return
 	}
 	userLocation := &purchase_entity.Location{
@@ -50,9 +52,9 @@ func (c *PurchaseController) HandleGetMerchantsNearby(w http.ResponseWriter, r *
 		Long: long,
 	}
 
-	err = validator_helper.ValidatePayload(userLocation)
+	err = validator_helper.ValidatePayload(userLocation, r.Header.Get("Accept-Language"))
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Request doesn't pass validation")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
@@ -60,23 +62,48 @@ func (c *PurchaseController) HandleGetMerchantsNearby(w http.ResponseWriter, r *
 
 	params := &purchase_entity.MerchantNearbyQueryParams{
 		Id:       query.Get("merchantId"),
-		Limit:    5,
-		Offset:   0,
 		Name:     query.Get("name"),
 		Category: query.Get("merchantCategory"),
+		Sort:     query.Get("sort"),
+		Items: item_entity.MerchantItemsParams{
+			Limit:    purchase_entity.DefaultNearbyItemLimit,
+			Name:     query.Get("itemName"),
+			Category: query.Get("productCategory"),
+		},
 	}
 
-	if limit := query.Get("limit"); limit != "" {
-		params.Limit, _ = strconv.Atoi(limit)
+	if params.Sort != purchase_entity.SortByNewest && params.Sort != purchase_entity.SortByName {
+		params.Sort = purchase_entity.SortByDistance
+	}
+	page, err := pagination_helper.ParsePage(query, params.Sort)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+	params.Page = page
+
+	if maxDistance := query.Get("maxDistance"); maxDistance != "" {
+		value, err := strconv.ParseFloat(maxDistance, 64)
+		// the comparisons are false for NaN, so it is rejected too
+		if err != nil || !(value > 0 && value <= purchase_entity.MaxNearbyDistanceKm) {
+			http_helper.ResponseProblem(w, r, purchase_exception.ErrInvalidMaxDistance)
+			return
+		}
+		params.MaxDistance = value
 	}
 
-	if offset := query.Get("offset"); offset != "" {
-		params.Offset, _ = strconv.Atoi(offset)
+	if itemLimit := query.Get("itemLimit"); itemLimit != "" {
+		value, err := strconv.Atoi(itemLimit)
+		if err != nil || value < 0 || value > purchase_entity.MaxNearbyItemLimit {
+			http_helper.ResponseProblem(w, r, purchase_exception.ErrInvalidItemLimit)
+			return
+		}
+		params.Items.Limit = value
 	}
 
 	merchantsNearbyResponse, err := c.Service.GetMerchantsNearby(r.Context(), userLocation, params)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
@@ -86,17 +113,17 @@ func (c *PurchaseController) HandleGetMerchantsNearby(w http.ResponseWriter, r *
 func (c *PurchaseController) HandleUserEstimateOrder(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not a user")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotUser)
 		return
 	}
 
 	userId, ok := r.Context().Value(middlewares.ContextUserIdKey).(string)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "UserId type assertion failed", "UserId not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 
@@ -104,31 +131,19 @@ func (c *PurchaseController) HandleUserEstimateOrder(w http.ResponseWriter, r *h
 
 	err := http_helper.DecodeJSON(r, payload)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Failed to decode JSON")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
-	err = validator_helper.ValidatePayload(payload)
+	err = validator_helper.ValidatePayload(payload, r.Header.Get("Accept-Language"))
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Request doesn't pass validation")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
 	userEstimateResponse, err := c.Service.EstimateOrder(r.Context(), userId, payload)
-	if errors.Is(err, purchase_exception.ErrDistanceTooFar) {
-		http_helper.ResponseError(w, http.StatusBadRequest, "Bad request error", err.Error())
-		return
-	}
-	if errors.Is(err, merchant_exception.ErrMerchantIdNotFound) {
-		http_helper.ResponseError(w, http.StatusNotFound, "Not found error", err.Error())
-		return
-	}
-	if errors.Is(err, item_exception.ErrItemIdNotFound) {
-		http_helper.ResponseError(w, http.StatusNotFound, "Not found error", err.Error())
-		return
-	}
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
@@ -138,17 +153,17 @@ func (c *PurchaseController) HandleUserEstimateOrder(w http.ResponseWriter, r *h
 func (c *PurchaseController) HandleUserOrder(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not a user")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotUser)
 		return
 	}
 
 	userId, ok := r.Context().Value(middlewares.ContextUserIdKey).(string)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "UserId type assertion failed", "UserId not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 
@@ -156,23 +171,19 @@ func (c *PurchaseController) HandleUserOrder(w http.ResponseWriter, r *http.Requ
 
 	err := http_helper.DecodeJSON(r, payload)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Failed to decode JSON")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
-	err = validator_helper.ValidatePayload(payload)
+	err = validator_helper.ValidatePayload(payload, r.Header.Get("Accept-Language"))
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusBadRequest, err.Error(), "Request doesn't pass validation")
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
 	userOrderResponse, err := c.Service.CreateOrder(r.Context(), userId, payload)
-	if errors.Is(err, purchase_exception.ErrEstimateIdNotFound) {
-		http_helper.ResponseError(w, http.StatusNotFound, "Not found error", err.Error())
-		return
-	}
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
 		return
 	}
 
@@ -182,17 +193,17 @@ func (c *PurchaseController) HandleUserOrder(w http.ResponseWriter, r *http.Requ
 func (c *PurchaseController) HandleGetUserOrders(w http.ResponseWriter, r *http.Request) {
 	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "IsAdmin type assertion failed", "IsAdmin not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 	if isAdmin {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "you're not a user")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotUser)
 		return
 	}
 
 	userId, ok := r.Context().Value(middlewares.ContextUserIdKey).(string)
 	if !ok {
-		http_helper.ResponseError(w, http.StatusUnauthorized, "UserId type assertion failed", "UserId not found in the context")
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
 		return
 	}
 
@@ -200,25 +211,57 @@ func (c *PurchaseController) HandleGetUserOrders(w http.ResponseWriter, r *http.
 
 	params := &purchase_entity.OrderQueryParams{
 		MerchantId: query.Get("merchantId"),
-		Limit:      5,
-		Offset:     0,
 		Name:       query.Get("name"),
 		Category:   query.Get("merchantCategory"),
 	}
 
-	if limit := query.Get("limit"); limit != "" {
-		params.Limit, _ = strconv.Atoi(limit)
-	}
-
-	if offset := query.Get("offset"); offset != "" {
-		params.Offset, _ = strconv.Atoi(offset)
+	page, err := pagination_helper.ParsePage(query, pagination_entity.SortCreatedAtDesc)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
 	}
+	params.Page = page
 
 	userOrdersResponse, err := c.Service.GetUserOrders(r.Context(), userId, params)
 	if err != nil {
-		http_helper.ResponseError(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	// the history was a bare list before cursors existed, it only gains its
+	// meta once a client asks for cursors with a cursor parameter, empty for
+	// the first page
+	if !query.Has("cursor") {
+		http_helper.EncodeJSON(w, http.StatusOK, userOrdersResponse.Data)
 		return
 	}
 
 	http_helper.EncodeJSON(w, http.StatusOK, userOrdersResponse)
 }
+
+// HandleUserOrderDelivered lets the user confirm an order arrived.
+func (c *PurchaseController) HandleUserOrderDelivered(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+	if isAdmin {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotUser)
+		return
+	}
+
+	userId, ok := r.Context().Value(middlewares.ContextUserIdKey).(string)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+
+	orderDeliveredResponse, err := c.Service.DeliverOrder(r.Context(), chi.URLParam(r, "orderId"), userId, time.Now())
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, http.StatusOK, orderDeliveredResponse)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	search_entity "github.com/danzBraham/beli-mang/internal/entities/search"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
+	search_exception "github.com/danzBraham/beli-mang/internal/exceptions/search"
+	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	"github.com/danzBraham/beli-mang/internal/http/middlewares"
+	"github.com/danzBraham/beli-mang/internal/services"
+)
+
+type SearchController struct {
+	Service services.SearchService
+}
+
+func NewSearchController(service services.SearchService) *SearchController {
+	return &SearchController{Service: service}
+}
+
+// HandleSearch finds merchants by name, category or the items they sell,
+// ranked by relevance and, when lat and long are sent, by distance too.
+func (c *SearchController) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool); !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+
+	query := r.URL.Query()
+	params := &search_entity.SearchQueryParams{
+		Query:     strings.TrimSpace(query.Get("q")),
+		Category:  query.Get("merchantCategory"),
+		Limit:     search_entity.DefaultLimit,
+		ItemLimit: search_entity.DefaultItemLimit,
+	}
+
+	length := utf8.RuneCountInString(params.Query)
+	if length < search_entity.MinQueryLength || length > search_entity.MaxQueryLength {
+		http_helper.ResponseProblem(w, r, search_exception.ErrInvalidQuery)
+		return
+	}
+
+	lat, long := query.Get("lat"), query.Get("long")
+	if lat != "" || long != "" {
+		location, err := parseSearchLocation(lat, long)
+		if err != nil {
+			http_helper.ResponseProblem(w, r, err)
+			return
+		}
+		params.Location = location
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > search_entity.MaxLimit {
+			http_helper.ResponseProblem(w, r, search_exception.ErrInvalidLimit)
+			return
+		}
+		params.Limit = value
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			http_helper.ResponseProblem(w, r, search_exception.ErrInvalidOffset)
+			return
+		}
+		params.Offset = value
+	}
+
+	if itemLimit := query.Get("itemLimit"); itemLimit != "" {
+		value, err := strconv.Atoi(itemLimit)
+		if err != nil || value < 0 || value > search_entity.MaxItemLimit {
+			http_helper.ResponseProblem(w, r, search_exception.ErrInvalidItemLimit)
+			return
+		}
+		params.ItemLimit = value
+	}
+
+	searchResponse, err := c.Service.Search(r.Context(), params)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeJSON(w, http.StatusOK, searchResponse)
+}
+
+func parseSearchLocation(lat, long string) (*purchase_entity.Location, error) {
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil, search_exception.ErrInvalidLocation
+	}
+	longitude, err := strconv.ParseFloat(long, 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil, search_exception.ErrInvalidLocation
+	}
+	return &purchase_entity.Location{Lat: latitude, Long: longitude}, nil
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
+	zone_entity "github.com/danzBraham/beli-mang/internal/entities/zone"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
+	geo_exception "github.com/danzBraham/beli-mang/internal/exceptions/geo"
+	zone_exception "github.com/danzBraham/beli-mang/internal/exceptions/zone"
+	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+	"github.com/danzBraham/beli-mang/internal/http/middlewares"
+	"github.com/danzBraham/beli-mang/internal/services"
+)
+
+type ZoneController struct {
+	Service services.ZoneService
+}
+
+func NewZoneController(service services.ZoneService) *ZoneController {
+	return &ZoneController{Service: service}
+}
+
+func (c *ZoneController) HandleGetZones(w http.ResponseWriter, r *http.Request) {
+	isAdmin, ok := r.Context().Value(middlewares.ContextIsAdminKey).(bool)
+	if !ok {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrUnknownClaims)
+		return
+	}
+	if !isAdmin {
+		http_helper.ResponseProblem(w, r, auth_exception.ErrNotAdmin)
+		return
+	}
+
+	query := r.URL.Query()
+	params := &zone_entity.ZoneQueryParams{
+		Precision: zone_entity.DefaultPrecision,
+	}
+
+	if precision := query.Get("precision"); precision != "" {
+		value, err := strconv.Atoi(precision)
+		if err != nil || value < zone_entity.MinPrecision || value > zone_entity.MaxPrecision {
+			http_helper.ResponseProblem(w, r, zone_exception.ErrInvalidPrecision)
+			return
+		}
+		params.Precision = value
+	}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		value, err := parseBBox(bbox)
+		if err != nil {
+			http_helper.ResponseProblem(w, r, err)
+			return
+		}
+		params.BBox = value
+	}
+
+	for _, bound := range []struct {
+		key   string
+		value **time.Time
+	}{{"from", &params.From}, {"to", &params.To}} {
+		if raw := query.Get(bound.key); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				http_helper.ResponseProblem(w, r, zone_exception.ErrInvalidTimeRange)
+				return
+			}
+			*bound.value = &t
+		}
+	}
+	if params.From != nil && params.To != nil && !params.From.Before(*params.To) {
+		http_helper.ResponseProblem(w, r, zone_exception.ErrInvalidTimeRange)
+		return
+	}
+
+	zones, err := c.Service.GetZones(r.Context(), params)
+	if err != nil {
+		http_helper.ResponseProblem(w, r, err)
+		return
+	}
+
+	http_helper.EncodeGeoJSON(w, http.StatusOK, zones)
+}
+
+// parseBBox reads a "minLong,minLat,maxLong,maxLat" viewport.
+func parseBBox(value string) (*geo_entity.BBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, geo_exception.ErrInvalidBBox
+	}
+
+	coordinates := make([]float64, len(parts))
+	for i, part := range parts {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, geo_exception.ErrInvalidBBox
+		}
+		coordinates[i] = coordinate
+	}
+
+	bbox := &geo_entity.BBox{
+		MinLong: coordinates[0],
+		MinLat:  coordinates[1],
+		MaxLong: coordinates[2],
+		MaxLat:  coordinates[3],
+	}
+	if bbox.MinLong < -180 || bbox.MaxLong > 180 || bbox.MinLat < -90 || bbox.MaxLat > 90 ||
+		bbox.MinLong >= bbox.MaxLong || bbox.MinLat >= bbox.MaxLat {
+		return nil, geo_exception.ErrInvalidBBox
+	}
+	return bbox, nil
+}
@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"strings"
 
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
 	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
 	jwt_helper "github.com/danzBraham/beli-mang/internal/helpers/jwt"
+	"github.com/danzBraham/beli-mang/internal/logger"
 )
 
 type ContextKey string
@@ -16,29 +18,42 @@ var (
 	ContextIsAdminKey ContextKey = "isAdmin"
 )
 
-func Authenticate(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "Missing Authorization header")
-			return
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == "" {
-			http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", "Invalid Authorization header")
-			return
-		}
-
-		jwtPayload, err := jwt_helper.VerifyToken(tokenString)
-		if err != nil {
-			http_helper.ResponseError(w, http.StatusUnauthorized, "Unauthorized error", err.Error())
-			return
-		}
-
-		ctx := context.WithValue(r.Context(), ContextUserIdKey, jwtPayload.UserId)
-		ctx = context.WithValue(ctx, ContextIsAdminKey, jwtPayload.IsAdmin)
-
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// UserVerifier checks that the user behind a valid token may still use the API.
+type UserVerifier interface {
+	VerifyActive(ctx context.Context, userId string) error
+}
+
+func Authenticate(secret string, users UserVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http_helper.ResponseProblem(w, r, auth_exception.ErrMissingToken)
+				return
+			}
+
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			if tokenString == "" {
+				http_helper.ResponseProblem(w, r, auth_exception.ErrInvalidToken)
+				return
+			}
+
+			jwtPayload, err := jwt_helper.VerifyToken(secret, tokenString)
+			if err != nil {
+				http_helper.ResponseProblem(w, r, err)
+				return
+			}
+
+			if err := users.VerifyActive(r.Context(), jwtPayload.UserId); err != nil {
+				http_helper.ResponseProblem(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ContextUserIdKey, jwtPayload.UserId)
+			ctx = context.WithValue(ctx, ContextIsAdminKey, jwtPayload.IsAdmin)
+			ctx = logger.Annotate(ctx, "user_id", jwtPayload.UserId, "is_admin", jwtPayload.IsAdmin)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
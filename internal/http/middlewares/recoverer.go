@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
+)
+
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			http_helper.ResponseProblem(w, r, exceptions.ErrInternal.WithCause(fmt.Errorf("panic: %v\n%s", rec, debug.Stack())))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
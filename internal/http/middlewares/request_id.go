@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+var ContextRequestIdKey ContextKey = "requestId"
+
+// RequestID honors a well-formed incoming X-Request-ID or generates one, echoes
+// it back and stores a logger tagged with it (and the trace) in the context.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(RequestIdHeader)
+		if !isValidRequestId(requestId) {
+			requestId = ulid.Make().String()
+		}
+		w.Header().Set(RequestIdHeader, requestId)
+
+		ctx := context.WithValue(r.Context(), ContextRequestIdKey, requestId)
+
+		attrs := []any{"request_id", requestId}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			attrs = append(attrs,
+				"trace_id", spanContext.TraceID().String(),
+				"span_id", spanContext.SpanID().String(),
+			)
+		}
+		ctx = logger.With(ctx, attrs...)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isValidRequestId(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
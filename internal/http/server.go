@@ -1,37 +1,65 @@
 package http
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/danzBraham/beli-mang/internal/config"
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
 	http_helper "github.com/danzBraham/beli-mang/internal/helpers/http"
 	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
 	"github.com/danzBraham/beli-mang/internal/http/controllers"
 	"github.com/danzBraham/beli-mang/internal/http/middlewares"
+	"github.com/danzBraham/beli-mang/internal/metrics"
 	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/routing"
 	"github.com/danzBraham/beli-mang/internal/services"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// Worker is a long running background task. It must return once ctx is cancelled.
+type Worker func(ctx context.Context)
+
 type APIServer struct {
-	Addr string
-	DB   *pgxpool.Pool
+	Config   *config.Config
+	DB       *pgxpool.Pool
+	Router   routing.Router
+	Geocoder geocoding.Geocoder
+	workers  []Worker
+	health   services.HealthService
 }
 
-func NewAPIServer(addr string, db *pgxpool.Pool) *APIServer {
+func NewAPIServer(cfg *config.Config, db *pgxpool.Pool, router routing.Router, geocoder geocoding.Geocoder) *APIServer {
+	prometheus.MustRegister(metrics.NewPoolCollector(db))
+
 	return &APIServer{
-		Addr: addr,
-		DB:   db,
+		Config:   cfg,
+		DB:       db,
+		Router:   router,
+		Geocoder: geocoder,
 	}
 }
 
-func (s *APIServer) Launch() error {
+func (s *APIServer) AddWorker(worker Worker) {
+	s.workers = append(s.workers, worker)
+}
+
+func (s *APIServer) routes() http.Handler {
 	r := chi.NewRouter()
 
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(middlewares.Tracing)
+	r.Use(middlewares.RequestID)
+	r.Use(middlewares.AccessLog)
+	r.Use(middlewares.Metrics)
+	r.Use(middlewares.Recoverer)
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Welcome to Beli Mang API"))
@@ -39,15 +67,25 @@ func (s *APIServer) Launch() error {
 
 	validator_helper.InitCustomValidation()
 
+	// Health domain
+	healthRepository := repositories.NewHealthRepository(s.DB)
+	s.health = services.NewHealthService(healthRepository, s.Config.AWS)
+	healthController := controllers.NewHealthController(s.health)
+
+	r.Get("/healthz", healthController.HandleLiveness)
+	r.Get("/readyz", healthController.HandleReadiness)
+	r.Handle("/metrics", promhttp.Handler())
+
 	// User domain
 	userRepository := repositories.NewUserRepository(s.DB)
-	userService := services.NewUserService(userRepository)
+	userService := services.NewUserService(userRepository, s.Config.Auth)
+	authenticate := middlewares.Authenticate(s.Config.Auth.JWTSecret, userService)
 	userController := controllers.NewUserController(userService)
 	adminController := controllers.NewAdminController(userService)
 
 	// Merchant domain
 	merchantRepository := repositories.NewMerchantRepository(s.DB)
-	merchantService := services.NewMerchantService(merchantRepository)
+	merchantService := services.NewMerchantService(merchantRepository, s.Geocoder)
 	merchantController := controllers.NewMerchantController(merchantService)
 
 	// Item domain
@@ -55,53 +93,147 @@ func (s *APIServer) Launch() error {
 	itemService := services.NewItemService(itemRepository, merchantRepository)
 	itemController := controllers.NewItemController(itemService)
 
+	// Delivery time model
+	etaRepository := repositories.NewEtaRepository(s.DB)
+	etaService := services.NewEtaService(etaRepository, s.Config.ETA)
+	s.AddWorker(etaService.RunCalibration)
+
+	// Address book
+	addressRepository := repositories.NewAddressRepository(s.DB)
+	addressService := services.NewAddressService(addressRepository, s.Geocoder)
+	addressController := controllers.NewAddressController(addressService)
+
 	// Purchase domain
 	purchaseRepository := repositories.NewPurchaseRepository(s.DB)
-	purchaseService := services.NewPurchaseService(purchaseRepository, merchantRepository, itemRepository)
+	purchaseService := services.NewPurchaseService(purchaseRepository, merchantRepository, itemRepository, addressRepository, s.Router, routing.NewHaversineRouter(s.Config.Routing.FallbackSpeedKmh), s.Geocoder, etaService, s.Config.Purchase)
 	purchaseController := controllers.NewPurchaseController(purchaseService)
 
+	// Bulk import and export
+	importService := services.NewImportService(merchantRepository, itemRepository, s.Geocoder)
+	importController := controllers.NewImportController(importService)
+
+	// Search
+	searchRepository := repositories.NewSearchRepository(s.DB)
+	searchService := services.NewSearchService(searchRepository)
+	searchController := controllers.NewSearchController(searchService)
+
+	// Zone aggregation
+	zoneRepository := repositories.NewZoneRepository(s.DB)
+	zoneService := services.NewZoneService(zoneRepository)
+	zoneController := controllers.NewZoneController(zoneService)
+
 	// Media domain
-	mediaController := controllers.NewMediaController()
+	mediaController := controllers.NewMediaController(s.Config.AWS)
 
 	r.Route("/admin", func(r chi.Router) {
 		r.Mount("/", adminController.Routes())
-		r.Mount("/merchants", merchantController.Routes())
-		r.Mount("/merchants/{merchantId}/items", itemController.Routes())
+		r.Group(func(r chi.Router) {
+			r.Use(authenticate)
+			r.Post("/merchants/import", importController.HandleImportMerchants)
+			r.Get("/merchants/export", importController.HandleExportMerchants)
+			r.Post("/items/import", importController.HandleImportItems)
+			r.Get("/items/export", importController.HandleExportItems)
+			r.Post("/merchants/{merchantId}/items/import", importController.HandleImportItems)
+			r.Get("/merchants/{merchantId}/items/export", importController.HandleExportItems)
+			r.Get("/zones", zoneController.HandleGetZones)
+		})
+		r.With(authenticate).Mount("/merchants", merchantController.Routes())
+		r.With(authenticate).Mount("/merchants/{merchantId}/items", itemController.Routes())
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Use(middlewares.Authenticate)
+		r.Use(authenticate)
 		r.Get("/merchants/nearby/{lat},{long}", purchaseController.HandleGetMerchantsNearby)
+		r.Get("/merchants/within", merchantController.HandleGetMerchantsWithin)
+		// POST carries the polygon for clients that cannot send a GET body
+		r.Post("/merchants/within", merchantController.HandleGetMerchantsWithin)
+		r.Get("/search", searchController.HandleSearch)
 	})
 
 	r.Route("/users", func(r chi.Router) {
 		r.Mount("/", userController.Routes())
 		r.Group(func(r chi.Router) {
-			r.Use(middlewares.Authenticate)
+			r.Use(authenticate)
 			r.Post("/estimate", purchaseController.HandleUserEstimateOrder)
 			r.Post("/orders", purchaseController.HandleUserOrder)
 			r.Get("/orders", purchaseController.HandleGetUserOrders)
+			r.Post("/orders/{orderId}/delivered", purchaseController.HandleUserOrderDelivered)
+			r.Mount("/addresses", addressController.Routes())
 		})
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Use(middlewares.Authenticate)
+		r.Use(authenticate)
 		r.Post("/image", mediaController.HandleUploadImage)
 	})
 
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		http_helper.ResponseError(w, http.StatusNotFound, "Not found error", "Route does not exists")
+		http_helper.ResponseProblem(w, r, exceptions.ErrRouteNotFound)
 	})
 
 	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-		http_helper.ResponseError(w, http.StatusMethodNotAllowed, "Method not allowed error", "Method is not allowed")
+		http_helper.ResponseProblem(w, r, exceptions.ErrMethodNotAllowed)
 	})
 
-	server := http.Server{
-		Addr:    s.Addr,
-		Handler: r,
+	return r
+}
+
+// Launch serves HTTP until ctx is cancelled, then drains in-flight requests
+// and stops the background workers before returning.
+func (s *APIServer) Launch(ctx context.Context) error {
+	addr := s.Config.App.Addr()
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadTimeout:       s.Config.App.ReadTimeout,
+		ReadHeaderTimeout: s.Config.App.ReadHeaderTimeout,
+		WriteTimeout:      s.Config.App.WriteTimeout,
+		IdleTimeout:       s.Config.App.IdleTimeout,
+		MaxHeaderBytes:    s.Config.App.MaxHeaderBytes,
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for _, worker := range s.workers {
+		wg.Add(1)
+		go func(worker Worker) {
+			defer wg.Done()
+			worker(workerCtx)
+		}(worker)
 	}
 
-	log.Printf("Server listening on %s\n", s.Addr)
-	return server.ListenAndServe()
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "addr", addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+	case <-ctx.Done():
+		s.health.MarkShuttingDown()
+		if delay := s.Config.App.ShutdownDelay; delay > 0 {
+			slog.Info("readiness set to failing, waiting before draining", "delay", delay)
+			time.Sleep(delay)
+		}
+
+		slog.Info("shutting down server, draining in-flight requests", "timeout", s.Config.App.ShutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.Config.App.ShutdownTimeout)
+		defer cancel()
+		if err = server.Shutdown(shutdownCtx); err != nil {
+			server.Close()
+		}
+	}
+
+	stopWorkers()
+	wg.Wait()
+	slog.Info("background workers stopped")
+
+	return err
 }
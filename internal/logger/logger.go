@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+)
+
+type contextKey struct{}
+
+type annotationsKey struct{}
+
+type annotations struct {
+	mu    sync.Mutex
+	attrs []any
+}
+
+func New(w io.Writer, cfg config.LogConfig) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: level, AddSource: cfg.AddSource}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	if cfg.SampleRate < 1 {
+		handler = &samplingHandler{Handler: handler, rate: cfg.SampleRate}
+	}
+
+	return slog.New(handler), nil
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", level)
+}
+
+// WithContext returns a copy of ctx carrying l.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request-scoped logger, or the default logger when
+// ctx does not carry one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// With adds attributes to the logger carried by ctx.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
+
+// WithAnnotations prepares ctx to collect attributes added further down the
+// handler chain with Annotate, so they can be read back by the access log.
+func WithAnnotations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, annotationsKey{}, &annotations{})
+}
+
+// Annotate adds attributes to the request-scoped logger and records them for
+// the access log entry written when the request completes.
+func Annotate(ctx context.Context, args ...any) context.Context {
+	if a, ok := ctx.Value(annotationsKey{}).(*annotations); ok {
+		a.mu.Lock()
+		a.attrs = append(a.attrs, args...)
+		a.mu.Unlock()
+	}
+	return With(ctx, args...)
+}
+
+// Annotations returns the attributes recorded with Annotate.
+func Annotations(ctx context.Context) []any {
+	a, ok := ctx.Value(annotationsKey{}).(*annotations)
+	if !ok {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]any(nil), a.attrs...)
+}
+
+// samplingHandler keeps only a fraction of records below warning level so
+// that busy endpoints don't flood the log pipeline. Warnings and errors are
+// always kept.
+type samplingHandler struct {
+	slog.Handler
+	rate float64
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < slog.LevelWarn && rand.Float64() >= h.rate {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), rate: h.rate}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), rate: h.rate}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "belimang"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by chi route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Database query latency by repository method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+
+	EstimatesCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "estimates_created_total",
+		Help:      "Order estimates successfully calculated.",
+	})
+
+	EstimatesRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "estimates_rejected_total",
+		Help:      "Order estimates rejected by business rules.",
+	}, []string{"reason"})
+
+	OrdersPlaced = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_placed_total",
+		Help:      "Orders placed from an estimate.",
+	})
+
+	OrdersDelivered = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_delivered_total",
+		Help:      "Orders marked delivered.",
+	})
+
+	Uploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploads_total",
+		Help:      "Image uploads by result.",
+	}, []string{"result"})
+
+	RouteFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "routing",
+		Name:      "fallbacks_total",
+		Help:      "Routes answered by the straight line fallback, by reason.",
+	}, []string{"reason"})
+
+	GeocoderRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "geocoder",
+		Name:      "requests_total",
+		Help:      "Geocoder lookups by provider, operation and result.",
+	}, []string{"provider", "operation", "result"})
+)
+
+// TimeQuery starts timing a repository method; call the returned func when the
+// method returns, typically with defer.
+func TimeQuery(repository, method string) func() {
+	start := time.Now()
+	return func() {
+		DBQueryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
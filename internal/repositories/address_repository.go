@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	address_entity "github.com/danzBraham/beli-mang/internal/entities/address"
+	address_exception "github.com/danzBraham/beli-mang/internal/exceptions/address"
+	"github.com/danzBraham/beli-mang/internal/metrics"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AddressRepository interface {
+	CountAddresses(ctx context.Context, userId string) (int, error)
+	VerifyLabel(ctx context.Context, userId, label, exceptId string) (bool, error)
+	CreateAddress(ctx context.Context, address *address_entity.Address) error
+	GetAddresses(ctx context.Context, userId string) ([]*address_entity.Address, error)
+	GetAddressById(ctx context.Context, userId, addressId string) (*address_entity.Address, error)
+	GetDefaultAddress(ctx context.Context, userId string) (*address_entity.Address, error)
+	UpdateAddress(ctx context.Context, address *address_entity.Address) error
+	DeleteAddress(ctx context.Context, userId, addressId string) error
+}
+
+type AddressRepositoryImpl struct {
+	DB *pgxpool.Pool
+}
+
+func NewAddressRepository(db *pgxpool.Pool) AddressRepository {
+	return &AddressRepositoryImpl{DB: db}
+}
+
+const addressColumns = `id, user_id, label,
+							ST_Y(location::geometry) AS latitude,
+							ST_X(location::geometry) AS longitude,
+							street, notes, is_default, created_at, updated_at`
+
+func scanAddress(row pgx.Row) (*address_entity.Address, error) {
+	var address address_entity.Address
+	var timeCreated, timeUpdated time.Time
+	err := row.Scan(
+		&address.Id,
+		&address.UserId,
+		&address.Label,
+		&address.Location.Lat,
+		&address.Location.Long,
+		&address.Street,
+		&address.Notes,
+		&address.IsDefault,
+		&timeCreated,
+		&timeUpdated,
+	)
+	if err != nil {
+		return nil, err
+	}
+	address.CreatedAt = timeCreated.Format(time.RFC3339)
+	address.UpdatedAt = timeUpdated.Format(time.RFC3339)
+	return &address, nil
+}
+
+func (r *AddressRepositoryImpl) CountAddresses(ctx context.Context, userId string) (int, error) {
+	defer metrics.TimeQuery("AddressRepository", "CountAddresses")()
+
+	var count int
+	query := `SELECT COUNT(*) FROM user_addresses WHERE user_id = $1`
+	err := r.DB.QueryRow(ctx, query, userId).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// VerifyLabel reports whether the user has another address, not exceptId,
+// with the label.
+func (r *AddressRepositoryImpl) VerifyLabel(ctx context.Context, userId, label, exceptId string) (bool, error) {
+	defer metrics.TimeQuery("AddressRepository", "VerifyLabel")()
+
+	var one int
+	query := `SELECT 1 FROM user_addresses WHERE user_id = $1 AND label = $2 AND id <> $3`
+	err := r.DB.QueryRow(ctx, query, userId, label, exceptId).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateAddress saves the address. It takes over the default when asked to,
+// and becomes the default anyway when the user has none.
+func (r *AddressRepositoryImpl) CreateAddress(ctx context.Context, address *address_entity.Address) error {
+	defer metrics.TimeQuery("AddressRepository", "CreateAddress")()
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(ctx, tx, address.UserId); err != nil {
+				return err
+			}
+		}
+
+		location := fmt.Sprintf("SRID=4326;POINT(%v %v)", address.Location.Long, address.Location.Lat)
+		query := `INSERT INTO user_addresses (id, user_id, label, location, street, notes, is_default)
+							VALUES ($1, $2, $3, $4, $5, $6,
+								$7 OR NOT EXISTS (SELECT 1 FROM user_addresses WHERE user_id = $2 AND is_default))
+							RETURNING is_default`
+		return tx.QueryRow(ctx, query,
+			address.Id,
+			address.UserId,
+			address.Label,
+			location,
+			address.Street,
+			address.Notes,
+			address.IsDefault,
+		).Scan(&address.IsDefault)
+	})
+}
+
+// GetAddresses lists the user's addresses, the default first.
+func (r *AddressRepositoryImpl) GetAddresses(ctx context.Context, userId string) ([]*address_entity.Address, error) {
+	defer metrics.TimeQuery("AddressRepository", "GetAddresses")()
+
+	query := `SELECT ` + addressColumns + `
+						FROM user_addresses
+						WHERE user_id = $1
+						ORDER BY is_default DESC, created_at DESC, id DESC`
+	rows, err := r.DB.Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []*address_entity.Address{}
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (r *AddressRepositoryImpl) GetAddressById(ctx context.Context, userId, addressId string) (*address_entity.Address, error) {
+	defer metrics.TimeQuery("AddressRepository", "GetAddressById")()
+
+	query := `SELECT ` + addressColumns + `
+						FROM user_addresses
+						WHERE id = $1 AND user_id = $2`
+	address, err := scanAddress(r.DB.QueryRow(ctx, query, addressId, userId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, address_exception.ErrAddressIdNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// GetDefaultAddress returns the user's default address, nil when none is
+// saved.
+func (r *AddressRepositoryImpl) GetDefaultAddress(ctx context.Context, userId string) (*address_entity.Address, error) {
+	defer metrics.TimeQuery("AddressRepository", "GetDefaultAddress")()
+
+	query := `SELECT ` + addressColumns + `
+						FROM user_addresses
+						WHERE user_id = $1 AND is_default`
+	address, err := scanAddress(r.DB.QueryRow(ctx, query, userId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// UpdateAddress replaces the saved fields, taking over the default when
+// asked to. The default address stays the default until another address
+// takes it over, so the user always has one.
+func (r *AddressRepositoryImpl) UpdateAddress(ctx context.Context, address *address_entity.Address) error {
+	defer metrics.TimeQuery("AddressRepository", "UpdateAddress")()
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(ctx, tx, address.UserId); err != nil {
+				return err
+			}
+		}
+
+		var timeCreated, timeUpdated time.Time
+		location := fmt.Sprintf("SRID=4326;POINT(%v %v)", address.Location.Long, address.Location.Lat)
+		query := `UPDATE user_addresses
+							SET label = $3, location = $4, street = $5, notes = $6, is_default = $7 OR is_default, updated_at = NOW()
+							WHERE id = $1 AND user_id = $2
+							RETURNING is_default, created_at, updated_at`
+		err := tx.QueryRow(ctx, query,
+			address.Id,
+			address.UserId,
+			address.Label,
+			location,
+			address.Street,
+			address.Notes,
+			address.IsDefault,
+		).Scan(&address.IsDefault, &timeCreated, &timeUpdated)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return address_exception.ErrAddressIdNotFound
+		}
+		if err != nil {
+			return err
+		}
+		address.CreatedAt = timeCreated.Format(time.RFC3339)
+		address.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		return nil
+	})
+}
+
+// DeleteAddress removes the address. When it was the default, the most
+// recently saved remaining address becomes the default.
+func (r *AddressRepositoryImpl) DeleteAddress(ctx context.Context, userId, addressId string) error {
+	defer metrics.TimeQuery("AddressRepository", "DeleteAddress")()
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		var wasDefault bool
+		query := `DELETE FROM user_addresses WHERE id = $1 AND user_id = $2 RETURNING is_default`
+		err := tx.QueryRow(ctx, query, addressId, userId).Scan(&wasDefault)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return address_exception.ErrAddressIdNotFound
+		}
+		if err != nil || !wasDefault {
+			return err
+		}
+
+		query = `UPDATE user_addresses SET is_default = TRUE
+							WHERE id = (
+								SELECT id FROM user_addresses
+								WHERE user_id = $1
+								ORDER BY created_at DESC, id DESC
+								LIMIT 1
+							)`
+		_, err = tx.Exec(ctx, query, userId)
+		return err
+	})
+}
+
+func clearDefaultAddress(ctx context.Context, tx pgx.Tx, userId string) error {
+	query := `UPDATE user_addresses SET is_default = FALSE WHERE user_id = $1 AND is_default`
+	_, err := tx.Exec(ctx, query, userId)
+	return err
+}
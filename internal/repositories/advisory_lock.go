@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdvisoryLock is a session advisory lock. It is held for as long as the
+// connection it was taken on stays open, so the connection is kept out of
+// the pool until the lock is released.
+type AdvisoryLock struct {
+	conn *pgxpool.Conn
+	key  int64
+}
+
+func tryAdvisoryLock(ctx context.Context, db *pgxpool.Pool, key int64) (*AdvisoryLock, error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	if !locked {
+		conn.Release()
+		return nil, nil
+	}
+	return &AdvisoryLock{conn: conn, key: key}, nil
+}
+
+// Held reports whether the connection holding the lock is still alive. The
+// server drops the lock with the connection, so another instance may have
+// taken it since.
+func (l *AdvisoryLock) Held(ctx context.Context) bool {
+	return l.conn.Ping(ctx) == nil
+}
+
+func (l *AdvisoryLock) Release() {
+	// use a fresh context so the lock is released even after cancellation
+	if _, err := l.conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		slog.Error("failed to release advisory lock", "key", l.key, "error", err)
+		// the lock goes away with the connection
+		l.conn.Hijack().Close(context.Background())
+		return
+	}
+	l.conn.Release()
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	eta_entity "github.com/danzBraham/beli-mang/internal/entities/eta"
+	"github.com/danzBraham/beli-mang/internal/metrics"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// calibrationLockKey identifies the advisory lock held by the one instance
+// that calibrates the speed profiles.
+const calibrationLockKey int64 = 7240531058438
+
+type EtaRepository interface {
+	GetSpeedProfiles(ctx context.Context) ([]*eta_entity.SpeedProfile, error)
+	UpsertSpeedProfiles(ctx context.Context, profiles []*eta_entity.SpeedProfile) error
+	TryCalibrationLock(ctx context.Context) (*AdvisoryLock, error)
+	GetDeliveries(ctx context.Context, since time.Time) ([]*eta_entity.Delivery, error)
+}
+
+type EtaRepositoryImpl struct {
+	DB *pgxpool.Pool
+}
+
+func NewEtaRepository(db *pgxpool.Pool) EtaRepository {
+	return &EtaRepositoryImpl{DB: db}
+}
+
+func (r *EtaRepositoryImpl) GetSpeedProfiles(ctx context.Context) ([]*eta_entity.SpeedProfile, error) {
+	defer metrics.TimeQuery("EtaRepository", "GetSpeedProfiles")()
+
+	query := `SELECT hour_of_week, factor, factor_low, factor_high, samples, updated_at
+						FROM speed_profiles
+						ORDER BY hour_of_week`
+	rows, err := r.DB.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []*eta_entity.SpeedProfile{}
+	for rows.Next() {
+		var profile eta_entity.SpeedProfile
+		var timeUpdated time.Time
+		err := rows.Scan(
+			&profile.HourOfWeek,
+			&profile.Factor,
+			&profile.FactorLow,
+			&profile.FactorHigh,
+			&profile.Samples,
+			&timeUpdated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		profile.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		profiles = append(profiles, &profile)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// UpsertSpeedProfiles stores the given profiles in one transaction, so readers
+// never see a half calibrated week. Hours missing from profiles keep the
+// profile they had.
+func (r *EtaRepositoryImpl) UpsertSpeedProfiles(ctx context.Context, profiles []*eta_entity.SpeedProfile) error {
+	defer metrics.TimeQuery("EtaRepository", "UpsertSpeedProfiles")()
+
+	query := `INSERT INTO speed_profiles (hour_of_week, factor, factor_low, factor_high, samples)
+						VALUES ($1, $2, $3, $4, $5)
+						ON CONFLICT (hour_of_week) DO UPDATE
+						SET factor = EXCLUDED.factor,
+							factor_low = EXCLUDED.factor_low,
+							factor_high = EXCLUDED.factor_high,
+							samples = EXCLUDED.samples,
+							updated_at = NOW()`
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, p := range profiles {
+			batch.Queue(query, p.HourOfWeek, p.Factor, p.FactorLow, p.FactorHigh, p.Samples)
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+// TryCalibrationLock takes the advisory lock of the calibrating instance on a
+// connection of its own. It returns nil when another instance holds it.
+func (r *EtaRepositoryImpl) TryCalibrationLock(ctx context.Context) (*AdvisoryLock, error) {
+	return tryAdvisoryLock(ctx, r.DB, calibrationLockKey)
+}
+
+// GetDeliveries returns the delivered orders placed since the given time
+// whose estimate recorded the route it was based on.
+func (r *EtaRepositoryImpl) GetDeliveries(ctx context.Context, since time.Time) ([]*eta_entity.Delivery, error) {
+	defer metrics.TimeQuery("EtaRepository", "GetDeliveries")()
+
+	query := `SELECT o.created_at, o.delivered_at, e.route_duration_seconds, e.preparation_minutes
+						FROM orders o
+						JOIN estimates e ON e.id = o.estimate_id
+						WHERE o.delivered_at IS NOT NULL
+						AND o.created_at >= $1
+						AND e.route_duration_seconds > 0
+						AND e.preparation_minutes IS NOT NULL`
+	rows, err := r.DB.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*eta_entity.Delivery{}
+	for rows.Next() {
+		var delivery eta_entity.Delivery
+		err := rows.Scan(
+			&delivery.OrderedAt,
+			&delivery.DeliveredAt,
+			&delivery.RouteDurationSeconds,
+			&delivery.PreparationMinutes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
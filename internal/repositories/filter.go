@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"strconv"
+	"strings"
+
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+)
+
+// filter collects the predicates of a list query with the arguments they
+// bind. A list and its total are built from the same filter so they always
+// agree on which rows match.
+type filter struct {
+	predicates []string
+	args       []interface{}
+}
+
+// bind adds value to the arguments and returns its placeholder.
+func (f *filter) bind(value interface{}) string {
+	f.args = append(f.args, value)
+	return "$" + strconv.Itoa(len(f.args))
+}
+
+func (f *filter) where(predicate string) {
+	f.predicates = append(f.predicates, predicate)
+}
+
+// clause returns the WHERE clause, empty when nothing is filtered.
+func (f *filter) clause() string {
+	if len(f.predicates) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(f.predicates, ` AND `)
+}
+
+var merchantCategories = map[string]bool{
+	merchant_entity.SmallRestaurant:       true,
+	merchant_entity.MediumRestaurant:      true,
+	merchant_entity.LargeRestaurant:       true,
+	merchant_entity.MerchandiseRestaurant: true,
+	merchant_entity.BoothKiosk:            true,
+	merchant_entity.ConvenienceStore:      true,
+}
+
+var itemCategories = map[string]bool{
+	item_entity.Beverage:   true,
+	item_entity.Food:       true,
+	item_entity.Snack:      true,
+	item_entity.Condiments: true,
+	item_entity.Additions:  true,
+}
+
+// whereCategory filters column by category. An unknown category matches
+// nothing rather than failing the request.
+func (f *filter) whereCategory(column, category string, categories map[string]bool) {
+	if category == "" {
+		return
+	}
+	if !categories[category] {
+		f.where(`FALSE`)
+		return
+	}
+	f.where(column + ` = ` + f.bind(category))
+}
+
+// envelope binds bbox and returns it as a lon/lat geometry.
+func (f *filter) envelope(bbox *geo_entity.BBox) string {
+	return `ST_MakeEnvelope(` + f.bind(bbox.MinLong) + `, ` + f.bind(bbox.MinLat) + `, ` +
+		f.bind(bbox.MaxLong) + `, ` + f.bind(bbox.MaxLat) + `, 4326)`
+}
+
+// inBBox matches a geography column against a lon/lat envelope. The &&
+// pre-check lets the GIST index serve the lookup, but on geography it
+// compares geocentric boxes and would also match points outside a
+// viewport, so the envelope is checked exactly in planar lon/lat as well.
+func inBBox(column, envelope string) string {
+	return column + ` && ` + envelope + `::geography AND ST_Intersects(` + column + `::geometry, ` + envelope + `)`
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestFilterEnvelope(t *testing.T) {
+	f := &filter{}
+	f.where(`name ILIKE ` + f.bind("%kopi%"))
+
+	got := f.envelope(&geo_entity.BBox{MinLong: 106.7, MinLat: -6.3, MaxLong: 106.9, MaxLat: -6.1})
+	if want := "ST_MakeEnvelope($2, $3, $4, $5, 4326)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if want := []interface{}{"%kopi%", 106.7, -6.3, 106.9, -6.1}; !reflect.DeepEqual(f.args, want) {
+		t.Errorf("got arguments %v, want %v", f.args, want)
+	}
+}
+
+// TestInBBox runs the predicate against PostGIS, e.g.
+//
+//	TEST_DATABASE_URL=postgres://... go test -run InBBox ./internal/repositories/
+func TestInBBox(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	// a viewport far from the equator, where the great circle between the
+	// northern corners bulges to about 70.07 degrees
+	query := `SELECT ` + inBBox(`location`, `ST_MakeEnvelope(10, 60, 20, 70, 4326)`) + `
+		FROM (SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography AS location) point`
+
+	cases := []struct {
+		name      string
+		long, lat float64
+		want      bool
+	}{
+		{name: "inside", long: 15, lat: 65, want: true},
+		{name: "just outside the northern edge", long: 15, lat: 70.05, want: false},
+		{name: "just outside the eastern edge", long: 20.01, lat: 65, want: false},
+		{name: "far outside", long: 40, lat: 10, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got bool
+			if err := pool.QueryRow(ctx, query, c.long, c.lat).Scan(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	geocoding_entity "github.com/danzBraham/beli-mang/internal/entities/geocoding"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	"github.com/danzBraham/beli-mang/internal/metrics"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type GeocoderRepository interface {
+	Geocode(ctx context.Context, address string) (*geocoding_entity.Place, error)
+	Reverse(ctx context.Context, location purchase_entity.Location, maxDistanceM float64) (*geocoding_entity.Place, error)
+	ImportAddresses(ctx context.Context, next func() (*geocoding_entity.Address, error), replace bool) (int64, error)
+}
+
+type GeocoderRepositoryImpl struct {
+	DB *pgxpool.Pool
+}
+
+func NewGeocoderRepository(db *pgxpool.Pool) GeocoderRepository {
+	return &GeocoderRepositoryImpl{DB: db}
+}
+
+// Geocode returns the address whose words best match the query, nil when
+// none contains them all.
+func (r *GeocoderRepositoryImpl) Geocode(ctx context.Context, address string) (*geocoding_entity.Place, error) {
+	defer metrics.TimeQuery("GeocoderRepository", "Geocode")()
+
+	var place geocoding_entity.Place
+	query := `SELECT address,
+							ST_Y(location::geometry) AS latitude,
+							ST_X(location::geometry) AS longitude
+						FROM geocoder_addresses, websearch_to_tsquery('simple', $1) q
+						WHERE search @@ q
+						ORDER BY ts_rank_cd(search, q) DESC, length(address), id
+						LIMIT 1`
+	err := r.DB.QueryRow(ctx, query, address).Scan(&place.Address, &place.Location.Lat, &place.Location.Long)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &place, nil
+}
+
+// Reverse returns the address nearest to location within maxDistanceM, nil
+// when there is none that close.
+func (r *GeocoderRepositoryImpl) Reverse(ctx context.Context, location purchase_entity.Location, maxDistanceM float64) (*geocoding_entity.Place, error) {
+	defer metrics.TimeQuery("GeocoderRepository", "Reverse")()
+
+	var place geocoding_entity.Place
+	query := `WITH point AS (
+							SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography AS location
+						)
+						SELECT a.address,
+							ST_Y(a.location::geometry) AS latitude,
+							ST_X(a.location::geometry) AS longitude
+						FROM geocoder_addresses a, point p
+						WHERE ST_DWithin(a.location, p.location, $3)
+						ORDER BY a.location <-> p.location
+						LIMIT 1`
+	err := r.DB.QueryRow(ctx, query, location.Long, location.Lat, maxDistanceM).Scan(&place.Address, &place.Location.Lat, &place.Location.Long)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &place, nil
+}
+
+// ImportAddresses copies the addresses next yields until io.EOF in a single
+// transaction, replacing the whole dataset when replace is set. They are
+// staged first because COPY can't encode PostGIS types.
+func (r *GeocoderRepositoryImpl) ImportAddresses(ctx context.Context, next func() (*geocoding_entity.Address, error), replace bool) (int64, error) {
+	defer metrics.TimeQuery("GeocoderRepository", "ImportAddresses")()
+
+	var count int64
+	err := pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		staging := `CREATE TEMPORARY TABLE staged_addresses (
+									house_number VARCHAR(50), street TEXT, unit VARCHAR(50), city TEXT, region TEXT,
+									postcode VARCHAR(20), address TEXT, lat DOUBLE PRECISION, long DOUBLE PRECISION
+								) ON COMMIT DROP`
+		if _, err := tx.Exec(ctx, staging); err != nil {
+			return fmt.Errorf("create staging table: %w", err)
+		}
+
+		source := pgx.CopyFromFunc(func() ([]any, error) {
+			address, err := next()
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return []any{
+				nullable(address.HouseNumber), nullable(address.Street), nullable(address.Unit),
+				nullable(address.City), nullable(address.Region), nullable(address.Postcode),
+				address.Format(), address.Location.Lat, address.Location.Long,
+			}, nil
+		})
+		columns := []string{"house_number", "street", "unit", "city", "region", "postcode", "address", "lat", "long"}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"staged_addresses"}, columns, source); err != nil {
+			return fmt.Errorf("copy addresses: %w", err)
+		}
+
+		if replace {
+			if _, err := tx.Exec(ctx, `TRUNCATE geocoder_addresses`); err != nil {
+				return fmt.Errorf("remove addresses: %w", err)
+			}
+		}
+
+		query := `INSERT INTO geocoder_addresses (house_number, street, unit, city, region, postcode, address, location)
+							SELECT house_number, street, unit, city, region, postcode, address,
+								ST_SetSRID(ST_MakePoint(long, lat), 4326)::geography
+							FROM staged_addresses`
+		tag, err := tx.Exec(ctx, query)
+		if err != nil {
+			return fmt.Errorf("move addresses: %w", err)
+		}
+		count = tag.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// nullable stores an empty string as NULL.
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
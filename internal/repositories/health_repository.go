@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	health_exception "github.com/danzBraham/beli-mang/internal/exceptions/health"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	GetPostGISVersion(ctx context.Context) (string, error)
+	GetMigrationVersion(ctx context.Context) (version uint64, dirty bool, err error)
+}
+
+type HealthRepositoryImpl struct {
+	DB *pgxpool.Pool
+}
+
+func NewHealthRepository(db *pgxpool.Pool) HealthRepository {
+	return &HealthRepositoryImpl{DB: db}
+}
+
+func (r *HealthRepositoryImpl) Ping(ctx context.Context) error {
+	return r.DB.Ping(ctx)
+}
+
+func (r *HealthRepositoryImpl) GetPostGISVersion(ctx context.Context) (string, error) {
+	var version string
+	query := `SELECT extversion FROM pg_extension WHERE extname = 'postgis'`
+	err := r.DB.QueryRow(ctx, query).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", health_exception.ErrPostGISNotInstalled
+	}
+	if err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+func (r *HealthRepositoryImpl) GetMigrationVersion(ctx context.Context) (version uint64, dirty bool, err error) {
+	var exists bool
+	query := `SELECT to_regclass('schema_migrations') IS NOT NULL`
+	if err = r.DB.QueryRow(ctx, query).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, health_exception.ErrMigrationsNotApplied
+	}
+
+	query = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	err = r.DB.QueryRow(ctx, query).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
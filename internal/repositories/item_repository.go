@@ -3,10 +3,11 @@ package repositories
 import (
 	"context"
 	"errors"
-	"strconv"
 	"time"
 
 	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+	"github.com/danzBraham/beli-mang/internal/metrics"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -14,9 +15,12 @@ import (
 type ItemRepository interface {
 	VerifyId(ctx context.Context, itemId string) (bool, error)
 	CreateItem(ctx context.Context, item *item_entity.Item) error
-	GetItems(ctx context.Context, params *item_entity.ItemQueryParams) ([]*item_entity.Item, error)
-	GetItemsByMerchantId(ctx context.Context, merchantId string) ([]*item_entity.Item, error)
-	CountItems(ctx context.Context) (count int, err error)
+	GetItems(ctx context.Context, merchantId string, params *item_entity.ItemQueryParams) ([]*item_entity.Item, error)
+	GetItemsByMerchantIds(ctx context.Context, merchantIds []string, params *item_entity.MerchantItemsParams) (map[string][]*item_entity.Item, error)
+	GetItemsByIds(ctx context.Context, itemIds []string) (map[string]*item_entity.Item, error)
+	CountItems(ctx context.Context, merchantId string, params *item_entity.ItemQueryParams) (count int, err error)
+	CreateItems(ctx context.Context, items []*item_entity.Item) error
+	ExportItems(ctx context.Context, merchantId string, fn func(item *item_entity.Item) error) error
 }
 
 type ItemRepositoryImpl struct {
@@ -28,6 +32,8 @@ func NewItemRepository(db *pgxpool.Pool) ItemRepository {
 }
 
 func (r *ItemRepositoryImpl) VerifyId(ctx context.Context, itemId string) (bool, error) {
+	defer metrics.TimeQuery("ItemRepository", "VerifyId")()
+
 	var one int
 	query := `SELECT 1 FROM items WHERE id = $1`
 	err := r.DB.QueryRow(ctx, query, itemId).Scan(&one)
@@ -41,6 +47,8 @@ func (r *ItemRepositoryImpl) VerifyId(ctx context.Context, itemId string) (bool,
 }
 
 func (r *ItemRepositoryImpl) CreateItem(ctx context.Context, item *item_entity.Item) error {
+	defer metrics.TimeQuery("ItemRepository", "CreateItem")()
+
 	query := `INSERT INTO items (id, name, category, price, image_url, merchant_id)
 						VALUES ($1, $2, $3, $4, $5, $6)`
 	_, err := r.DB.Exec(ctx, query, &item.Id, &item.Name, &item.Category, &item.Price, &item.ImageURL, &item.MerchantId)
@@ -50,55 +58,21 @@ func (r *ItemRepositoryImpl) CreateItem(ctx context.Context, item *item_entity.I
 	return nil
 }
 
-func (r *ItemRepositoryImpl) GetItems(ctx context.Context, params *item_entity.ItemQueryParams) ([]*item_entity.Item, error) {
-	query := `SELECT id, name, category, price, image_url, merchant_id, created_at, updated_at
-						FROM items 
-						WHERE 1 = 1`
-	args := []interface{}{}
-	argId := 1
-
-	if params.Id != "" {
-		query += ` AND id = $` + strconv.Itoa(argId)
-		args = append(args, params.Id)
-		argId++
-	}
-
-	if params.Name != "" {
-		query += ` AND name ILIKE $` + strconv.Itoa(argId)
-		args = append(args, "%"+params.Name+"%")
-		argId++
-	}
-
-	validCategories := map[string]bool{
-		item_entity.Beverage:   true,
-		item_entity.Food:       true,
-		item_entity.Snack:      true,
-		item_entity.Condiments: true,
-		item_entity.Additions:  true,
-	}
-
-	if params.Category != "" {
-		if !validCategories[params.Category] {
-			return []*item_entity.Item{}, nil
-		}
-		query += ` AND category = $` + strconv.Itoa(argId)
-		args = append(args, params.Category)
-		argId++
-	}
+// GetItems reads a page of the merchant's items.
+func (r *ItemRepositoryImpl) GetItems(ctx context.Context, merchantId string, params *item_entity.ItemQueryParams) ([]*item_entity.Item, error) {
+	defer metrics.TimeQuery("ItemRepository", "GetItems")()
 
-	switch params.CreatedAt {
-	case "asc":
-		query += ` ORDER BY created_at ASC`
-	case "desc":
-		query += ` ORDER BY created_at DESC`
-	default:
-		query += ` ORDER BY created_at DESC`
+	f := itemFilter(merchantId, params)
+	order := keyset{columns: []string{"created_at", "id"}, descending: params.CreatedAt != "asc"}
+	if params.Page.Cursor != nil {
+		f.where(order.after(params.Page, f, params.Page.Cursor.CreatedAt, params.Page.Cursor.Id))
 	}
 
-	query += ` LIMIT $` + strconv.Itoa(argId) + ` OFFSET $` + strconv.Itoa(argId+1)
-	args = append(args, params.Limit, params.Offset)
+	query := `SELECT id, name, category, price, image_url, merchant_id, created_at, updated_at
+						FROM items` + f.clause() + `
+						ORDER BY ` + order.orderBy(params.Page) + pageLimit(params.Page, f)
 
-	rows, err := r.DB.Query(ctx, query, args...)
+	rows, err := r.DB.Query(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +97,7 @@ func (r *ItemRepositoryImpl) GetItems(ctx context.Context, params *item_entity.I
 		}
 		item.CreatedAt = timeCreated.Format(time.RFC3339)
 		item.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		item.Position = pagination_entity.Cursor{Id: item.Id, CreatedAt: &timeCreated}
 		items = append(items, &item)
 	}
 
@@ -133,17 +108,43 @@ func (r *ItemRepositoryImpl) GetItems(ctx context.Context, params *item_entity.I
 	return items, nil
 }
 
-func (r *ItemRepositoryImpl) GetItemsByMerchantId(ctx context.Context, merchantId string) ([]*item_entity.Item, error) {
+// GetItemsByMerchantIds loads the items of many merchants in one round trip,
+// newest first and at most params.Limit per merchant.
+func (r *ItemRepositoryImpl) GetItemsByMerchantIds(ctx context.Context, merchantIds []string, params *item_entity.MerchantItemsParams) (map[string][]*item_entity.Item, error) {
+	defer metrics.TimeQuery("ItemRepository", "GetItemsByMerchantIds")()
+
+	itemsByMerchant := make(map[string][]*item_entity.Item, len(merchantIds))
+	if len(merchantIds) == 0 {
+		return itemsByMerchant, nil
+	}
+
+	f := &filter{}
+	f.where(`merchant_id = ANY(` + f.bind(merchantIds) + `)`)
+
+	if params.Name != "" {
+		f.where(`name ILIKE ` + f.bind("%"+params.Name+"%"))
+	}
+
+	f.whereCategory(`category`, params.Category, itemCategories)
+
 	query := `SELECT id, name, category, price, image_url, merchant_id, created_at, updated_at
-						FROM items 
-						WHERE merchant_id = $1`
-	rows, err := r.DB.Query(ctx, query, merchantId)
+						FROM (
+							SELECT *, ROW_NUMBER() OVER (PARTITION BY merchant_id ORDER BY created_at DESC, id) AS rank
+							FROM items` + f.clause() + `
+						) ranked`
+
+	if params.Limit > 0 {
+		query += ` WHERE rank <= ` + f.bind(params.Limit)
+	}
+
+	query += ` ORDER BY merchant_id, rank`
+
+	rows, err := r.DB.Query(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	items := []*item_entity.Item{}
 	for rows.Next() {
 		var item item_entity.Item
 		var timeCreated, timeUpdated time.Time
@@ -162,7 +163,37 @@ func (r *ItemRepositoryImpl) GetItemsByMerchantId(ctx context.Context, merchantI
 		}
 		item.CreatedAt = timeCreated.Format(time.RFC3339)
 		item.UpdatedAt = timeUpdated.Format(time.RFC3339)
-		items = append(items, &item)
+		itemsByMerchant[item.MerchantId] = append(itemsByMerchant[item.MerchantId], &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return itemsByMerchant, nil
+}
+
+// GetItemsByIds returns the existing items among itemIds keyed by id.
+func (r *ItemRepositoryImpl) GetItemsByIds(ctx context.Context, itemIds []string) (map[string]*item_entity.Item, error) {
+	defer metrics.TimeQuery("ItemRepository", "GetItemsByIds")()
+
+	query := `SELECT id, name, category, price, image_url, merchant_id
+						FROM items
+						WHERE id = ANY($1)`
+	rows, err := r.DB.Query(ctx, query, itemIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make(map[string]*item_entity.Item, len(itemIds))
+	for rows.Next() {
+		var item item_entity.Item
+		err := rows.Scan(&item.Id, &item.Name, &item.Category, &item.Price, &item.ImageURL, &item.MerchantId)
+		if err != nil {
+			return nil, err
+		}
+		items[item.Id] = &item
 	}
 
 	if err := rows.Err(); err != nil {
@@ -172,11 +203,97 @@ func (r *ItemRepositoryImpl) GetItemsByMerchantId(ctx context.Context, merchantI
 	return items, nil
 }
 
-func (r *ItemRepositoryImpl) CountItems(ctx context.Context) (count int, err error) {
-	query := `SELECT COUNT(1) FROM items`
-	err = r.DB.QueryRow(ctx, query).Scan(&count)
+// CountItems counts the items GetItems pages through.
+func (r *ItemRepositoryImpl) CountItems(ctx context.Context, merchantId string, params *item_entity.ItemQueryParams) (count int, err error) {
+	defer metrics.TimeQuery("ItemRepository", "CountItems")()
+
+	f := itemFilter(merchantId, params)
+	query := `SELECT COUNT(1) FROM items` + f.clause()
+	err = r.DB.QueryRow(ctx, query, f.args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+func itemFilter(merchantId string, params *item_entity.ItemQueryParams) *filter {
+	f := &filter{}
+	f.where(`merchant_id = ` + f.bind(merchantId))
+
+	if params.Id != "" {
+		f.where(`id = ` + f.bind(params.Id))
+	}
+
+	if params.Name != "" {
+		f.where(`name ILIKE ` + f.bind("%"+params.Name+"%"))
+	}
+
+	f.whereCategory(`category`, params.Category, itemCategories)
+
+	return f
+}
+
+// CreateItems copies all items in one transaction.
+func (r *ItemRepositoryImpl) CreateItems(ctx context.Context, items []*item_entity.Item) error {
+	defer metrics.TimeQuery("ItemRepository", "CreateItems")()
+
+	rows := make([][]any, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, []any{item.Id, item.Name, item.Category, item.Price, item.ImageURL, item.MerchantId})
+	}
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		columns := []string{"id", "name", "category", "price", "image_url", "merchant_id"}
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"items"}, columns, pgx.CopyFromRows(rows))
+		return err
+	})
+}
+
+// ExportItems streams the items of a merchant to fn, or of every merchant
+// when merchantId is empty.
+func (r *ItemRepositoryImpl) ExportItems(ctx context.Context, merchantId string, fn func(item *item_entity.Item) error) error {
+	defer metrics.TimeQuery("ItemRepository", "ExportItems")()
+
+	query := `SELECT id, name, category, price, image_url, merchant_id, created_at, updated_at
+						FROM items
+						WHERE 1 = 1`
+	args := []interface{}{}
+
+	if merchantId != "" {
+		query += ` AND merchant_id = $1`
+		args = append(args, merchantId)
+	}
+
+	query += ` ORDER BY merchant_id, created_at, id`
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item item_entity.Item
+		var timeCreated, timeUpdated time.Time
+		err := rows.Scan(
+			&item.Id,
+			&item.Name,
+			&item.Category,
+			&item.Price,
+			&item.ImageURL,
+			&item.MerchantId,
+			&timeCreated,
+			&timeUpdated,
+		)
+		if err != nil {
+			return err
+		}
+		item.CreatedAt = timeCreated.Format(time.RFC3339)
+		item.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,126 @@
+package repositories_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The benchmarks run against a migrated and seeded database, e.g.
+//
+//	beli-mang seed --reset
+//	BENCH_DATABASE_URL=postgres://... go test -run '^$' -bench Items ./internal/repositories/
+const pageSize = 50
+
+func setupItemBenchmark(b *testing.B) (*pgxpool.Pool, []string) {
+	b.Helper()
+
+	url := os.Getenv("BENCH_DATABASE_URL")
+	if url == "" {
+		b.Skip("BENCH_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pool.Close)
+
+	rows, err := pool.Query(ctx, `SELECT id FROM merchants ORDER BY id LIMIT $1`, pageSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	merchantIds, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(merchantIds) < pageSize {
+		b.Skipf("need at least %d merchants, found %d", pageSize, len(merchantIds))
+	}
+
+	return pool, merchantIds
+}
+
+// itemsByMerchantId is the query the nearby path used to run per merchant,
+// kept here as the baseline.
+func itemsByMerchantId(ctx context.Context, pool *pgxpool.Pool, merchantId string) ([]*item_entity.Item, error) {
+	query := `SELECT id, name, category, price, image_url, merchant_id, created_at, updated_at
+						FROM items
+						WHERE merchant_id = $1`
+	rows, err := pool.Query(ctx, query, merchantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*item_entity.Item{}
+	for rows.Next() {
+		var item item_entity.Item
+		var timeCreated, timeUpdated time.Time
+		err := rows.Scan(
+			&item.Id,
+			&item.Name,
+			&item.Category,
+			&item.Price,
+			&item.ImageURL,
+			&item.MerchantId,
+			&timeCreated,
+			&timeUpdated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.CreatedAt = timeCreated.Format(time.RFC3339)
+		item.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// BenchmarkItemsPerMerchant is the previous nearby path, one query per merchant.
+func BenchmarkItemsPerMerchant(b *testing.B) {
+	pool, merchantIds := setupItemBenchmark(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, merchantId := range merchantIds {
+			if _, err := itemsByMerchantId(ctx, pool, merchantId); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkItemsByMerchantIds(b *testing.B) {
+	pool, merchantIds := setupItemBenchmark(b)
+	repo := repositories.NewItemRepository(pool)
+	ctx := context.Background()
+
+	cases := []struct {
+		name   string
+		params item_entity.MerchantItemsParams
+	}{
+		{"all", item_entity.MerchantItemsParams{}},
+		{"limit5", item_entity.MerchantItemsParams{Limit: 5}},
+		{"category", item_entity.MerchantItemsParams{Category: item_entity.Food}},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetItemsByMerchantIds(ctx, merchantIds, &c.params); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
+	"github.com/danzBraham/beli-mang/internal/metrics"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -17,7 +19,13 @@ type MerchantRepository interface {
 	CreateMerchant(ctx context.Context, merchant *merchant_entity.Merchant) error
 	GetMerchants(ctx context.Context, params *merchant_entity.MerchantQueryParams) ([]*merchant_entity.Merchant, error)
 	GetMerchantbyId(ctx context.Context, merchantId string) (*merchant_entity.Merchant, error)
-	CountMerhcants(ctx context.Context) (count int, err error)
+	CountMerchants(ctx context.Context, params *merchant_entity.MerchantQueryParams) (count int, err error)
+	CreateMerchants(ctx context.Context, merchants []*merchant_entity.Merchant) error
+	GetExistingIds(ctx context.Context, merchantIds []string) (map[string]bool, error)
+	GetPickupsByIds(ctx context.Context, merchantIds []string) (map[string]*merchant_entity.Pickup, error)
+	UpdatePreparationMinutes(ctx context.Context, merchantId string, minutes *int) error
+	GetMerchantsWithin(ctx context.Context, params *merchant_entity.MerchantWithinQueryParams, limit int) ([]*merchant_entity.MerchantCluster, error)
+	ExportMerchants(ctx context.Context, fn func(merchant *merchant_entity.Merchant) error) error
 }
 
 type MerchantRepositoryImpl struct {
@@ -29,6 +37,8 @@ func NewMerchantRepository(db *pgxpool.Pool) MerchantRepository {
 }
 
 func (r *MerchantRepositoryImpl) VerifyId(ctx context.Context, merchantId string) (bool, error) {
+	defer metrics.TimeQuery("MerchantRepository", "VerifyId")()
+
 	var one int
 	query := `SELECT 1 FROM merchants WHERE id = $1`
 	err := r.DB.QueryRow(ctx, query, merchantId).Scan(&one)
@@ -42,10 +52,12 @@ func (r *MerchantRepositoryImpl) VerifyId(ctx context.Context, merchantId string
 }
 
 func (r *MerchantRepositoryImpl) CreateMerchant(ctx context.Context, merchant *merchant_entity.Merchant) error {
+	defer metrics.TimeQuery("MerchantRepository", "CreateMerchant")()
+
 	location := fmt.Sprintf("SRID=4326;POINT(%v %v)", merchant.Location.Long, merchant.Location.Lat)
-	query := `INSERT INTO merchants (id, name, category, image_url, location, user_id)
-						VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.DB.Exec(ctx, query, &merchant.Id, &merchant.Name, &merchant.Category, &merchant.ImageURL, location, &merchant.UserId)
+	query := `INSERT INTO merchants (id, name, category, image_url, location, user_id, address, preparation_minutes)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.DB.Exec(ctx, query, &merchant.Id, &merchant.Name, &merchant.Category, &merchant.ImageURL, location, &merchant.UserId, merchant.Address, merchant.PreparationMinutes)
 	if err != nil {
 		return err
 	}
@@ -53,58 +65,22 @@ func (r *MerchantRepositoryImpl) CreateMerchant(ctx context.Context, merchant *m
 }
 
 func (r *MerchantRepositoryImpl) GetMerchants(ctx context.Context, params *merchant_entity.MerchantQueryParams) ([]*merchant_entity.Merchant, error) {
-	query := `SELECT id, name, category, image_url, 
-							ST_Y(location::geometry) AS latitude,
-							ST_X(location::geometry) AS longitude,
-							user_id, created_at, updated_at
-						FROM merchants 
-						WHERE 1 = 1`
-	args := []interface{}{}
-	argId := 1
+	defer metrics.TimeQuery("MerchantRepository", "GetMerchants")()
 
-	if params.Id != "" {
-		query += ` AND id = $` + strconv.Itoa(argId)
-		args = append(args, params.Id)
-		argId++
-	}
-
-	if params.Name != "" {
-		query += ` AND name ILIKE $` + strconv.Itoa(argId)
-		args = append(args, "%"+params.Name+"%")
-		argId++
+	f := merchantFilter(params)
+	order := keyset{columns: []string{"created_at", "id"}, descending: params.CreatedAt != "asc"}
+	if params.Page.Cursor != nil {
+		f.where(order.after(params.Page, f, params.Page.Cursor.CreatedAt, params.Page.Cursor.Id))
 	}
 
-	validCategories := map[string]bool{
-		merchant_entity.SmallRestaurant:       true,
-		merchant_entity.MediumRestaurant:      true,
-		merchant_entity.LargeRestaurant:       true,
-		merchant_entity.MerchandiseRestaurant: true,
-		merchant_entity.BoothKiosk:            true,
-		merchant_entity.ConvenienceStore:      true,
-	}
-
-	if params.Category != "" {
-		if !validCategories[params.Category] {
-			return []*merchant_entity.Merchant{}, nil
-		}
-		query += ` AND category = $` + strconv.Itoa(argId)
-		args = append(args, params.Category)
-		argId++
-	}
-
-	switch params.CreatedAt {
-	case "asc":
-		query += ` ORDER BY created_at ASC`
-	case "desc":
-		query += ` ORDER BY created_at DESC`
-	default:
-		query += ` ORDER BY created_at DESC`
-	}
-
-	query += ` LIMIT $` + strconv.Itoa(argId) + ` OFFSET $` + strconv.Itoa(argId+1)
-	args = append(args, params.Limit, params.Offset)
+	query := `SELECT id, name, category, image_url,
+							ST_Y(location::geometry) AS latitude,
+							ST_X(location::geometry) AS longitude,
+							user_id, address, created_at, updated_at
+						FROM merchants` + f.clause() + `
+						ORDER BY ` + order.orderBy(params.Page) + pageLimit(params.Page, f)
 
-	rows, err := r.DB.Query(ctx, query, args...)
+	rows, err := r.DB.Query(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +98,7 @@ func (r *MerchantRepositoryImpl) GetMerchants(ctx context.Context, params *merch
 			&merchant.Location.Lat,
 			&merchant.Location.Long,
 			&merchant.UserId,
+			&merchant.Address,
 			&timeCreated,
 			&timeUpdated,
 		)
@@ -130,6 +107,7 @@ func (r *MerchantRepositoryImpl) GetMerchants(ctx context.Context, params *merch
 		}
 		merchant.CreatedAt = timeCreated.Format(time.RFC3339)
 		merchant.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		merchant.Position = pagination_entity.Cursor{Id: merchant.Id, CreatedAt: &timeCreated}
 		merchants = append(merchants, &merchant)
 	}
 
@@ -141,6 +119,8 @@ func (r *MerchantRepositoryImpl) GetMerchants(ctx context.Context, params *merch
 }
 
 func (r *MerchantRepositoryImpl) GetMerchantbyId(ctx context.Context, merchantId string) (*merchant_entity.Merchant, error) {
+	defer metrics.TimeQuery("MerchantRepository", "GetMerchantbyId")()
+
 	var merchant merchant_entity.Merchant
 	var timeCreated, timeUpdated time.Time
 	query := `SELECT id, name, category, image_url, 
@@ -168,11 +148,243 @@ func (r *MerchantRepositoryImpl) GetMerchantbyId(ctx context.Context, merchantId
 	return &merchant, nil
 }
 
-func (r *MerchantRepositoryImpl) CountMerhcants(ctx context.Context) (count int, err error) {
-	query := `SELECT COUNT(1) FROM merchants`
-	err = r.DB.QueryRow(ctx, query).Scan(&count)
+// CountMerchants counts the merchants GetMerchants pages through.
+func (r *MerchantRepositoryImpl) CountMerchants(ctx context.Context, params *merchant_entity.MerchantQueryParams) (count int, err error) {
+	defer metrics.TimeQuery("MerchantRepository", "CountMerchants")()
+
+	f := merchantFilter(params)
+	query := `SELECT COUNT(1) FROM merchants` + f.clause()
+	err = r.DB.QueryRow(ctx, query, f.args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+func merchantFilter(params *merchant_entity.MerchantQueryParams) *filter {
+	f := &filter{}
+
+	if params.Id != "" {
+		f.where(`id = ` + f.bind(params.Id))
+	}
+
+	if params.Name != "" {
+		f.where(`name ILIKE ` + f.bind("%"+params.Name+"%"))
+	}
+
+	f.whereCategory(`category`, params.Category, merchantCategories)
+
+	return f
+}
+
+// CreateMerchants inserts all merchants in one transaction.
+func (r *MerchantRepositoryImpl) CreateMerchants(ctx context.Context, merchants []*merchant_entity.Merchant) error {
+	defer metrics.TimeQuery("MerchantRepository", "CreateMerchants")()
+
+	query := `INSERT INTO merchants (id, name, category, image_url, location, user_id, address, preparation_minutes)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	batch := &pgx.Batch{}
+	for _, merchant := range merchants {
+		location := fmt.Sprintf("SRID=4326;POINT(%v %v)", merchant.Location.Long, merchant.Location.Lat)
+		batch.Queue(query, merchant.Id, merchant.Name, merchant.Category, merchant.ImageURL, location, merchant.UserId, merchant.Address, merchant.PreparationMinutes)
+	}
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+// GetExistingIds reports which of the given merchant ids exist.
+func (r *MerchantRepositoryImpl) GetExistingIds(ctx context.Context, merchantIds []string) (map[string]bool, error) {
+	defer metrics.TimeQuery("MerchantRepository", "GetExistingIds")()
+
+	query := `SELECT id FROM merchants WHERE id = ANY($1)`
+	rows, err := r.DB.Query(ctx, query, merchantIds)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// GetPickupsByIds returns the location and preparation time of each
+// existing merchant.
+func (r *MerchantRepositoryImpl) GetPickupsByIds(ctx context.Context, merchantIds []string) (map[string]*merchant_entity.Pickup, error) {
+	defer metrics.TimeQuery("MerchantRepository", "GetPickupsByIds")()
+
+	query := `SELECT id, ST_Y(location::geometry) AS latitude, ST_X(location::geometry) AS longitude, preparation_minutes
+						FROM merchants
+						WHERE id = ANY($1)`
+	rows, err := r.DB.Query(ctx, query, merchantIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pickups := make(map[string]*merchant_entity.Pickup, len(merchantIds))
+	for rows.Next() {
+		var id string
+		var pickup merchant_entity.Pickup
+		if err := rows.Scan(&id, &pickup.Location.Lat, &pickup.Location.Long, &pickup.PreparationMinutes); err != nil {
+			return nil, err
+		}
+		pickups[id] = &pickup
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pickups, nil
+}
+
+// UpdatePreparationMinutes sets the merchant's preparation time, nil resets
+// it to the default.
+func (r *MerchantRepositoryImpl) UpdatePreparationMinutes(ctx context.Context, merchantId string, minutes *int) error {
+	defer metrics.TimeQuery("MerchantRepository", "UpdatePreparationMinutes")()
+
+	query := `UPDATE merchants SET preparation_minutes = $1, updated_at = NOW() WHERE id = $2`
+	tag, err := r.DB.Exec(ctx, query, minutes, merchantId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return merchant_exception.ErrMerchantIdNotFound
+	}
+	return nil
+}
+
+// ExportMerchants streams every merchant to fn, oldest first.
+func (r *MerchantRepositoryImpl) ExportMerchants(ctx context.Context, fn func(merchant *merchant_entity.Merchant) error) error {
+	defer metrics.TimeQuery("MerchantRepository", "ExportMerchants")()
+
+	query := `SELECT id, name, category, image_url,
+							ST_Y(location::geometry) AS latitude,
+							ST_X(location::geometry) AS longitude,
+							user_id, address, preparation_minutes, created_at, updated_at
+						FROM merchants
+						ORDER BY created_at, id`
+	rows, err := r.DB.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var merchant merchant_entity.Merchant
+		var timeCreated, timeUpdated time.Time
+		err := rows.Scan(
+			&merchant.Id,
+			&merchant.Name,
+			&merchant.Category,
+			&merchant.ImageURL,
+			&merchant.Location.Lat,
+			&merchant.Location.Long,
+			&merchant.UserId,
+			&merchant.Address,
+			&merchant.PreparationMinutes,
+			&timeCreated,
+			&timeUpdated,
+		)
+		if err != nil {
+			return err
+		}
+		merchant.CreatedAt = timeCreated.Format(time.RFC3339)
+		merchant.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		if err := fn(&merchant); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetMerchantsWithin groups the merchants inside the requested area by grid
+// cell when params.Zoom asks for clustering, and by merchant otherwise. The
+// area is matched against location directly so idx_merchants_location
+// serves the lookup.
+func (r *MerchantRepositoryImpl) GetMerchantsWithin(ctx context.Context, params *merchant_entity.MerchantWithinQueryParams, limit int) ([]*merchant_entity.MerchantCluster, error) {
+	defer metrics.TimeQuery("MerchantRepository", "GetMerchantsWithin")()
+
+	f := &filter{}
+
+	if params.BBox != nil {
+		f.where(inBBox(`location`, f.envelope(params.BBox)))
+	}
+
+	if params.Polygon != nil {
+		f.where(`ST_Intersects(location, ST_GeomFromGeoJSON(` + f.bind(string(params.Polygon)) + `)::geography)`)
+	}
+
+	if params.Name != "" {
+		f.where(`name ILIKE ` + f.bind("%"+params.Name+"%"))
+	}
+
+	f.whereCategory(`category`, params.Category, merchantCategories)
+
+	group := `id`
+	if params.Zoom != nil && *params.Zoom < merchant_entity.MaxClusterZoom {
+		group = `ST_SnapToGrid(geom, ` + f.bind(merchant_entity.ClusterCellDegrees(*params.Zoom)) + `)`
+	}
+
+	// the merchant columns are only meaningful for single merchant groups
+	query := `WITH matched AS (
+							SELECT id, name, category, image_url, created_at, location::geometry AS geom
+							FROM merchants` + f.clause() + `
+						)
+						SELECT COUNT(*),
+							ST_Y(ST_Centroid(ST_Collect(geom))) AS latitude,
+							ST_X(ST_Centroid(ST_Collect(geom))) AS longitude,
+							MIN(id), MIN(name), MIN(category), MIN(image_url), MIN(created_at)
+						FROM matched
+						GROUP BY ` + group + `
+						ORDER BY COUNT(*) DESC, MIN(created_at) DESC, MIN(id)
+						LIMIT ` + f.bind(limit)
+
+	rows, err := r.DB.Query(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clusters := []*merchant_entity.MerchantCluster{}
+	for rows.Next() {
+		var cluster merchant_entity.MerchantCluster
+		var merchant merchant_entity.Merchant
+		var timeCreated time.Time
+		err := rows.Scan(
+			&cluster.Count,
+			&cluster.Location.Lat,
+			&cluster.Location.Long,
+			&merchant.Id,
+			&merchant.Name,
+			&merchant.Category,
+			&merchant.ImageURL,
+			&timeCreated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if cluster.Count == 1 {
+			merchant.Location = cluster.Location
+			merchant.CreatedAt = timeCreated.Format(time.RFC3339)
+			cluster.Merchant = &merchant
+		}
+		clusters = append(clusters, &cluster)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
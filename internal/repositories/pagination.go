@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"strings"
+
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+)
+
+// keyset is the order of a list as the columns of its sort key, the last one
+// unique so that every row has its own position.
+type keyset struct {
+	columns    []string
+	descending bool
+}
+
+// after returns the predicate keeping the rows past the page cursor in the
+// direction the page is read. keys are the cursor values of the columns, in
+// order, and are bound to f.
+func (k keyset) after(page *pagination_entity.Page, f *filter, keys ...interface{}) string {
+	operator := ">"
+	if k.descending != page.Cursor.Backward {
+		operator = "<"
+	}
+
+	placeholders := make([]string, len(keys))
+	for i, key := range keys {
+		placeholders[i] = f.bind(key)
+	}
+	return `(` + strings.Join(k.columns, ", ") + `) ` + operator + ` (` + strings.Join(placeholders, ", ") + `)`
+}
+
+// orderBy returns the ORDER BY list, reversed when the page is read backward.
+func (k keyset) orderBy(page *pagination_entity.Page) string {
+	direction := " ASC"
+	if k.descending != (page.Cursor != nil && page.Cursor.Backward) {
+		direction = " DESC"
+	}
+
+	terms := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		terms[i] = column + direction
+	}
+	return strings.Join(terms, ", ")
+}
+
+// pageLimit returns the LIMIT and OFFSET of the page, bound to f. One row
+// more than the page is read to tell whether another page follows.
+func pageLimit(page *pagination_entity.Page, f *filter) string {
+	return ` LIMIT ` + f.bind(page.Limit+1) + ` OFFSET ` + f.bind(page.Offset)
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+)
+
+var newestKeyset = keyset{columns: []string{"created_at", "id"}, descending: true}
+
+var nameKeyset = keyset{columns: []string{"name", "id"}}
+
+func TestKeysetAfter(t *testing.T) {
+	cases := []struct {
+		name     string
+		keyset   keyset
+		backward bool
+		want     string
+	}{
+		{name: "ascending forward", keyset: nameKeyset, want: "(name, id) > ($2, $3)"},
+		{name: "ascending backward", keyset: nameKeyset, backward: true, want: "(name, id) < ($2, $3)"},
+		{name: "descending forward", keyset: newestKeyset, want: "(created_at, id) < ($2, $3)"},
+		{name: "descending backward", keyset: newestKeyset, backward: true, want: "(created_at, id) > ($2, $3)"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			page := &pagination_entity.Page{Cursor: &pagination_entity.Cursor{Backward: c.backward}}
+			// the keys are bound after the arguments already in the filter
+			f := &filter{}
+			f.where(`merchant_id = ` + f.bind("01HM"))
+
+			got := c.keyset.after(page, f, "key", "01HX")
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+			if want := []interface{}{"01HM", "key", "01HX"}; !reflect.DeepEqual(f.args, want) {
+				t.Errorf("got arguments %v, want %v", f.args, want)
+			}
+		})
+	}
+}
+
+func TestKeysetOrderBy(t *testing.T) {
+	cases := []struct {
+		name   string
+		keyset keyset
+		cursor *pagination_entity.Cursor
+		want   string
+	}{
+		{name: "ascending", keyset: nameKeyset, want: "name ASC, id ASC"},
+		{name: "ascending forward", keyset: nameKeyset, cursor: &pagination_entity.Cursor{}, want: "name ASC, id ASC"},
+		{name: "ascending backward", keyset: nameKeyset, cursor: &pagination_entity.Cursor{Backward: true}, want: "name DESC, id DESC"},
+		{name: "descending", keyset: newestKeyset, want: "created_at DESC, id DESC"},
+		{name: "descending forward", keyset: newestKeyset, cursor: &pagination_entity.Cursor{}, want: "created_at DESC, id DESC"},
+		{name: "descending backward", keyset: newestKeyset, cursor: &pagination_entity.Cursor{Backward: true}, want: "created_at ASC, id ASC"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.keyset.orderBy(&pagination_entity.Page{Cursor: c.cursor})
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	f := &filter{}
+	f.where(`id = ` + f.bind("01HX"))
+
+	got := pageLimit(&pagination_entity.Page{Limit: 5, Offset: 10}, f)
+	if want := " LIMIT $2 OFFSET $3"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// one more row than the page tells whether another page follows
+	if want := []interface{}{"01HX", 6, 10}; !reflect.DeepEqual(f.args, want) {
+		t.Errorf("got arguments %v, want %v", f.args, want)
+	}
+}
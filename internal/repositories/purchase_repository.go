@@ -4,23 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
 	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
 	purchase_exception "github.com/danzBraham/beli-mang/internal/exceptions/purchase"
-	formula_helper "github.com/danzBraham/beli-mang/internal/helpers/formula"
+	"github.com/danzBraham/beli-mang/internal/metrics"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PurchaseRepository interface {
-	GetMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) ([]*merchant_entity.GetMerchant, error)
-	CreateEstimateOrder(ctx context.Context, estimateOrder *purchase_entity.EstimateOrder, orderMerchants []*purchase_entity.OrderMerchant, orderItems []*purchase_entity.OrderItem) (*purchase_entity.EstimateOrder, error)
+	GetMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) ([]*purchase_entity.MerchantNearby, error)
+	CountMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) (count int, err error)
+	CreateEstimateOrder(ctx context.Context, estimateOrder *purchase_entity.EstimateOrder, orderMerchants []*purchase_entity.OrderMerchant, orderItems []*purchase_entity.OrderItem) error
 	CreateOrder(ctx context.Context, userOrder *purchase_entity.UserOrder) error
-	VerifyEstimateId(ctx context.Context, estimateId string) (bool, error)
-	GetOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) ([]*purchase_entity.GetUserOrder, error)
+	MarkOrderDelivered(ctx context.Context, orderId, userId string, deliveredAt time.Time) error
+	GetOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) ([]*purchase_entity.UserOrderHistory, error)
+	GetEstimateState(ctx context.Context, estimateId string) (*purchase_entity.EstimateState, error)
+	RepriceEstimate(ctx context.Context, estimateId string) (totalPrice int, err error)
+	VoidEstimate(ctx context.Context, estimateId string) error
 }
 
 type PurchaseRepositoryImpl struct {
@@ -31,65 +35,56 @@ func NewPurchaseRepository(db *pgxpool.Pool) PurchaseRepository {
 	return &PurchaseRepositoryImpl{DB: db}
 }
 
-func (r *PurchaseRepositoryImpl) GetMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) ([]*merchant_entity.GetMerchant, error) {
-	query := `
-		WITH user_location AS (
-			SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326) AS location
-		)
-		SELECT 
-			m.id, m.name, m.category, m.image_url,
-			ST_Y(m.location::geometry) AS latitude, ST_X(m.location::geometry) AS longitude, m.created_at
-		FROM merchants m, user_location ul
-		WHERE 1 = 1
-	`
-	args := []interface{}{location.Long, location.Lat}
-	argId := len(args) + 1
+func (r *PurchaseRepositoryImpl) GetMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) ([]*purchase_entity.MerchantNearby, error) {
+	defer metrics.TimeQuery("PurchaseRepository", "GetMerchantsNearby")()
 
-	if params.Id != "" {
-		query += ` AND m.id = $` + strconv.Itoa(argId)
-		args = append(args, params.Id)
-		argId++
-	}
+	f := nearbyFilter(location, params)
 
-	if params.Name != "" {
-		query += ` AND m.name ILIKE $` + strconv.Itoa(argId)
-		args = append(args, "%"+params.Name+"%")
-		argId++
+	// ordering by the <-> operator walks the GIST index nearest first
+	order := keyset{columns: []string{"(m.location <-> ul.location)", "m.id"}}
+	switch params.Sort {
+	case purchase_entity.SortByNewest:
+		order = keyset{columns: []string{"m.created_at", "m.id"}, descending: true}
+	case purchase_entity.SortByName:
+		order = keyset{columns: []string{"m.name", "m.id"}}
 	}
 
-	validCategories := map[string]bool{
-		merchant_entity.SmallRestaurant:       true,
-		merchant_entity.MediumRestaurant:      true,
-		merchant_entity.LargeRestaurant:       true,
-		merchant_entity.MerchandiseRestaurant: true,
-		merchant_entity.BoothKiosk:            true,
-		merchant_entity.ConvenienceStore:      true,
-	}
-
-	if params.Category != "" {
-		if !validCategories[params.Category] {
-			return []*merchant_entity.GetMerchant{}, nil
+	if cursor := params.Page.Cursor; cursor != nil {
+		keys := []interface{}{cursor.Distance, cursor.Id}
+		switch params.Sort {
+		case purchase_entity.SortByNewest:
+			keys = []interface{}{cursor.CreatedAt, cursor.Id}
+		case purchase_entity.SortByName:
+			keys = []interface{}{cursor.Name, cursor.Id}
 		}
-		query += ` AND m.category = $` + strconv.Itoa(argId)
-		args = append(args, params.Category)
-		argId++
+		f.where(order.after(params.Page, f, keys...))
 	}
 
-	query += ` ORDER BY m.location <-> ul.location`
-
-	query += ` LIMIT $` + strconv.Itoa(argId) + ` OFFSET $` + strconv.Itoa(argId+1)
-	args = append(args, params.Limit, params.Offset)
-
-	rows, err := r.DB.Query(ctx, query, args...)
+	query := `
+		WITH user_location AS (
+			SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography AS location
+		)
+		SELECT
+			m.id, m.name, m.category, m.image_url,
+			ST_Y(m.location::geometry) AS latitude, ST_X(m.location::geometry) AS longitude, m.created_at,
+			ST_Distance(m.location, ul.location) / 1000 AS distance_km,
+			m.location <-> ul.location AS knn_distance,
+			m.preparation_minutes
+		FROM merchants m, user_location ul` + f.clause() + `
+		ORDER BY ` + order.orderBy(params.Page) + pageLimit(params.Page, f)
+
+	rows, err := r.DB.Query(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	merchants := []*merchant_entity.GetMerchant{}
+	merchants := []*purchase_entity.MerchantNearby{}
 	for rows.Next() {
 		var merchant merchant_entity.GetMerchant
 		var timeCreated time.Time
+		var distanceKm, knnDistance float64
+		var preparationMinutes *int
 		err := rows.Scan(
 			&merchant.Id,
 			&merchant.Name,
@@ -98,12 +93,25 @@ func (r *PurchaseRepositoryImpl) GetMerchantsNearby(ctx context.Context, locatio
 			&merchant.Location.Lat,
 			&merchant.Location.Long,
 			&timeCreated,
+			&distanceKm,
+			&knnDistance,
+			&preparationMinutes,
 		)
 		if err != nil {
 			return nil, err
 		}
 		merchant.CreatedAt = timeCreated.Format(time.RFC3339)
-		merchants = append(merchants, &merchant)
+		merchants = append(merchants, &purchase_entity.MerchantNearby{
+			Merchant:           &merchant,
+			DistanceKm:         distanceKm,
+			PreparationMinutes: preparationMinutes,
+			Position: pagination_entity.Cursor{
+				Id:        merchant.Id,
+				CreatedAt: &timeCreated,
+				Distance:  &knnDistance,
+				Name:      &merchant.Name,
+			},
+		})
 	}
 
 	if err := rows.Err(); err != nil {
@@ -113,253 +121,237 @@ func (r *PurchaseRepositoryImpl) GetMerchantsNearby(ctx context.Context, locatio
 	return merchants, nil
 }
 
-func (r *PurchaseRepositoryImpl) CreateEstimateOrder(ctx context.Context, estimateOrder *purchase_entity.EstimateOrder, orderMerchants []*purchase_entity.OrderMerchant, orderItems []*purchase_entity.OrderItem) (*purchase_entity.EstimateOrder, error) {
-	tx, err := r.DB.Begin(ctx)
+// CountMerchantsNearby counts the merchants GetMerchantsNearby pages through.
+func (r *PurchaseRepositoryImpl) CountMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) (count int, err error) {
+	defer metrics.TimeQuery("PurchaseRepository", "CountMerchantsNearby")()
+
+	f := nearbyFilter(location, params)
+	query := `
+		WITH user_location AS (
+			SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography AS location
+		)
+		SELECT COUNT(1)
+		FROM merchants m, user_location ul` + f.clause()
+	err = r.DB.QueryRow(ctx, query, f.args...).Scan(&count)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback(ctx)
-			panic(p)
-		} else if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			err = tx.Commit(ctx)
-		}
-	}()
-
-	const MaxDistance = 3.0 // Max distance in km
-
-	// Collect all points for smallest enclosing circle calculation
-	points := []purchase_entity.Location{estimateOrder.UserLocation}
+	return count, nil
+}
 
-	getMerchantLocationQuery := `
-		SELECT ST_Y(location::geometry) AS latitude, ST_X(location::geometry) AS longitude
-		FROM merchants
-		WHERE id = $1
-	`
+// nearbyFilter binds the user's longitude and latitude as $1 and $2 for the
+// user_location the nearby queries start with.
+func nearbyFilter(location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) *filter {
+	f := &filter{}
+	f.bind(location.Long)
+	f.bind(location.Lat)
 
-	for _, orderMerchant := range orderMerchants {
-		var merchantLat, merchantLong float64
-		err := tx.QueryRow(ctx, getMerchantLocationQuery, orderMerchant.MerchantId).Scan(&merchantLat, &merchantLong)
-		if err != nil {
-			return nil, err
-		}
-		points = append(points, purchase_entity.Location{Lat: merchantLat, Long: merchantLong})
+	if params.Id != "" {
+		f.where(`m.id = ` + f.bind(params.Id))
 	}
 
-	// Check if any point exceeds the MaxDistance using smallest enclosing circle
-	circle := formula_helper.SmallestEnclosingCircle(points)
-	if circle.Radius > MaxDistance {
-		return nil, purchase_exception.ErrDistanceTooFar
+	if params.Name != "" {
+		f.where(`m.name ILIKE ` + f.bind("%"+params.Name+"%"))
 	}
 
-	// Create order
-	createEstimateQuery := `
-		INSERT INTO estimates (id, user_location)
-		VALUES ($1, $2)
-	`
-	location := fmt.Sprintf("SRID=4326;POINT(%v %v)", estimateOrder.UserLocation.Long, estimateOrder.UserLocation.Lat)
-	_, err = tx.Exec(ctx, createEstimateQuery, estimateOrder.Id, location)
-	if err != nil {
-		return nil, err
-	}
+	f.whereCategory(`m.category`, params.Category, merchantCategories)
 
-	// Create order merchants
-	createOrderMerchantQuery := `
-		INSERT INTO order_merchants (id, merchant_id, is_starting_point, estimate_id)
-		VALUES ($1, $2, $3, $4)
-	`
-	for _, orderMerchant := range orderMerchants {
-		_, err := tx.Exec(ctx, createOrderMerchantQuery, orderMerchant.Id, orderMerchant.MerchantId, orderMerchant.IsStartingPoint, orderMerchant.EstimateId)
-		if err != nil {
-			return nil, err
-		}
+	// ST_DWithin on geography uses the GIST index on merchants.location
+	if params.MaxDistance > 0 {
+		f.where(`ST_DWithin(m.location, ul.location, ` + f.bind(params.MaxDistance*1000) + `)`)
 	}
 
-	// Create order items
-	createOrderItemQuery := `
-		INSERT INTO order_items (id, item_id, quantity, total_item_price, order_merchant_id)
-		SELECT $1, $2, $3, $4 * price, $5 FROM items WHERE id = $6;
-	`
-	for _, orderItem := range orderItems {
-		_, err := tx.Exec(ctx, createOrderItemQuery,
-			orderItem.Id,
-			orderItem.ItemId,
-			orderItem.Quantity,
-			orderItem.Quantity,
-			orderItem.OrderMerchantId,
-			orderItem.ItemId,
+	return f
+}
+
+// CreateEstimateOrder stores an estimate whose prices and delivery time were
+// already calculated, copying its merchants and items in bulk.
+func (r *PurchaseRepositoryImpl) CreateEstimateOrder(ctx context.Context, estimateOrder *purchase_entity.EstimateOrder, orderMerchants []*purchase_entity.OrderMerchant, orderItems []*purchase_entity.OrderItem) error {
+	defer metrics.TimeQuery("PurchaseRepository", "CreateEstimateOrder")()
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		createEstimateQuery := `
+			INSERT INTO estimates (
+				id, user_location, user_address, address_id, address_label, courier_notes,
+				total_price, estimated_delivery_time,
+				route_distance_km, route_duration_seconds, preparation_minutes
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`
+		location := fmt.Sprintf("SRID=4326;POINT(%v %v)", estimateOrder.UserLocation.Long, estimateOrder.UserLocation.Lat)
+		_, err := tx.Exec(ctx, createEstimateQuery,
+			estimateOrder.Id,
+			location,
+			estimateOrder.UserAddress,
+			estimateOrder.AddressId,
+			estimateOrder.AddressLabel,
+			estimateOrder.CourierNotes,
+			estimateOrder.TotalPrice,
+			estimateOrder.EstimatedDeliveryTime,
+			estimateOrder.RouteDistanceKm,
+			estimateOrder.RouteDurationSeconds,
+			estimateOrder.PreparationMinutes,
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	}
 
-	// Set total merchant price
-	setTotalMerchantPriceQuery := `
-		UPDATE order_merchants
-		SET total_merchant_price = (
-			SELECT SUM(total_item_price) 
-			FROM order_items
-			WHERE order_merchant_id = $1
+		merchantRows := make([][]any, 0, len(orderMerchants))
+		for _, om := range orderMerchants {
+			merchantRows = append(merchantRows, []any{om.Id, om.MerchantId, om.TotalMerchantPrice, om.IsStartingPoint, om.EstimateId})
+		}
+		_, err = tx.CopyFrom(ctx,
+			pgx.Identifier{"order_merchants"},
+			[]string{"id", "merchant_id", "total_merchant_price", "is_starting_point", "estimate_id"},
+			pgx.CopyFromRows(merchantRows),
 		)
-		WHERE id = $1
-	`
-	for _, orderMerchant := range orderMerchants {
-		_, err := tx.Exec(ctx, setTotalMerchantPriceQuery, orderMerchant.Id)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	}
 
-	// Set total price
-	var orderId string
-	var totalPrice int
-	setTotalPriceQuery := `
-		UPDATE estimates
-		SET total_price = (
-			SELECT SUM(total_merchant_price) 
-			FROM order_merchants
-			WHERE estimate_id = $1
+		itemRows := make([][]any, 0, len(orderItems))
+		for _, oi := range orderItems {
+			itemRows = append(itemRows, []any{oi.Id, oi.ItemId, oi.Quantity, oi.TotalItemPrice, oi.OrderMerchantId})
+		}
+		_, err = tx.CopyFrom(ctx,
+			pgx.Identifier{"order_items"},
+			[]string{"id", "item_id", "quantity", "total_item_price", "order_merchant_id"},
+			pgx.CopyFromRows(itemRows),
 		)
-		WHERE id = $1
-		RETURNING id, total_price
-	`
-	err = tx.QueryRow(ctx, setTotalPriceQuery, estimateOrder.Id).Scan(&orderId, &totalPrice)
-	if err != nil {
-		return nil, err
-	}
+		return err
+	})
+}
 
-	// Calculate estimated delivery time
-	var maxDistance float64
-	for _, orderMerchant := range orderMerchants {
-		var merchantLat, merchantLong float64
-		err := tx.QueryRow(ctx, getMerchantLocationQuery, orderMerchant.MerchantId).Scan(&merchantLat, &merchantLong)
-		if err != nil {
-			return nil, err
-		}
+// CreateOrder places the order while holding the estimate lock, so an
+// estimate voided or ordered concurrently can't be ordered.
+func (r *PurchaseRepositoryImpl) CreateOrder(ctx context.Context, userOrder *purchase_entity.UserOrder) error {
+	defer metrics.TimeQuery("PurchaseRepository", "CreateOrder")()
 
-		distance := formula_helper.Distance(estimateOrder.UserLocation, purchase_entity.Location{Lat: merchantLat, Long: merchantLong})
-		if distance > maxDistance {
-			maxDistance = distance
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		if err := lockModifiableEstimate(ctx, tx, userOrder.EstimateId); err != nil {
+			return err
 		}
-	}
 
-	estimatedDeliveryTime := formula_helper.CalculateDeliveryTime(maxDistance)
+		query := `
+			INSERT INTO orders (id, estimate_id, user_id)
+			VALUES ($1, $2, $3)
+		`
+		_, err := tx.Exec(ctx, query, &userOrder.Id, &userOrder.EstimateId, &userOrder.UserId)
+		return err
+	})
+}
+
+// MarkOrderDelivered records when the order was delivered. An empty userId
+// marks any user's order.
+func (r *PurchaseRepositoryImpl) MarkOrderDelivered(ctx context.Context, orderId, userId string, deliveredAt time.Time) error {
+	defer metrics.TimeQuery("PurchaseRepository", "MarkOrderDelivered")()
 
-	// Update the order with estimated delivery time
-	updateEstimatedDeliveryTime := `
-		UPDATE estimates
-		SET estimated_delivery_time = $1
-		WHERE id = $2
-	`
-	_, err = tx.Exec(ctx, updateEstimatedDeliveryTime, estimatedDeliveryTime, estimateOrder.Id)
-	if err != nil {
-		return nil, err
+	f := &filter{}
+	f.where(`id = ` + f.bind(orderId))
+	if userId != "" {
+		f.where(`user_id = ` + f.bind(userId))
 	}
 
-	return &purchase_entity.EstimateOrder{
-		Id:                    orderId,
-		TotalPrice:            totalPrice,
-		EstimatedDeliveryTime: estimatedDeliveryTime,
-	}, nil
-}
+	var createdAt time.Time
+	var isDelivered bool
+	query := `SELECT created_at, delivered_at IS NOT NULL FROM orders` + f.clause() + ` FOR UPDATE`
 
-func (r *PurchaseRepositoryImpl) CreateOrder(ctx context.Context, userOrder *purchase_entity.UserOrder) error {
-	query := `
-		INSERT INTO orders (id, estimate_id, user_id)
-		VALUES ($1, $2, $3)
-	`
-	_, err := r.DB.Exec(ctx, query, &userOrder.Id, &userOrder.EstimateId, &userOrder.UserId)
-	if err != nil {
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, f.args...).Scan(&createdAt, &isDelivered)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return purchase_exception.ErrOrderIdNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if isDelivered {
+			return purchase_exception.ErrOrderDelivered
+		}
+		if deliveredAt.Before(createdAt) {
+			return purchase_exception.ErrInvalidDeliveredAt
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE orders SET delivered_at = $2, updated_at = NOW() WHERE id = $1`, orderId, deliveredAt)
 		return err
-	}
-	return nil
+	})
 }
 
-func (r *PurchaseRepositoryImpl) VerifyEstimateId(ctx context.Context, estimateId string) (bool, error) {
-	var one int
-	query := `SELECT 1 FROM estimates WHERE id = $1`
-	err := r.DB.QueryRow(ctx, query, estimateId).Scan(&one)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return false, nil
-	}
-	if err != nil {
-		return false, err
-	}
-	return true, nil
-}
+// GetOrders reads a page of the user's orders, newest first, with the
+// merchants and items matching the filters. The page counts orders, not the
+// merchant or item rows they are made of.
+func (r *PurchaseRepositoryImpl) GetOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) ([]*purchase_entity.UserOrderHistory, error) {
+	defer metrics.TimeQuery("PurchaseRepository", "GetOrders")()
 
-func (r *PurchaseRepositoryImpl) GetOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) ([]*purchase_entity.GetUserOrder, error) {
-	query := `
-		SELECT
-			o.id,
-			m.id, m.name, m.category, m.image_url, ST_Y(m.location::geometry) AS latitude, ST_X(m.location::geometry) AS longitude, m.created_at,
-			i.id, i.name, i.category, i.price, oi.quantity, i.image_url, i.created_at
-		FROM orders o
-		INNER JOIN order_merchants om ON om.estimate_id = o.estimate_id
-		INNER JOIN merchants m ON m.id = om.merchant_id
-		INNER JOIN order_items oi ON oi.order_merchant_id = om.id
-		INNER JOIN items i ON i.id = oi.item_id
-		WHERE o.user_id = $1
-	`
-	args := []interface{}{}
-	args = append(args, userId)
-	argId := 2
+	f := &filter{}
+	f.where(`o.user_id = ` + f.bind(userId))
 
 	if params.MerchantId != "" {
-		query += ` AND m.id = $` + strconv.Itoa(argId)
-		args = append(args, params.MerchantId)
-		argId++
+		f.where(`m.id = ` + f.bind(params.MerchantId))
 	}
 
 	if params.Name != "" {
-		query += ` AND m.name ILIKE $` + strconv.Itoa(argId) + ` OR i.name ILIKE $` + strconv.Itoa(argId)
-		args = append(args, "%"+params.Name+"%")
-		argId++
+		name := f.bind("%" + params.Name + "%")
+		f.where(`(m.name ILIKE ` + name + ` OR i.name ILIKE ` + name + `)`)
 	}
 
-	validCategories := map[string]bool{
-		merchant_entity.SmallRestaurant:       true,
-		merchant_entity.MediumRestaurant:      true,
-		merchant_entity.LargeRestaurant:       true,
-		merchant_entity.MerchandiseRestaurant: true,
-		merchant_entity.BoothKiosk:            true,
-		merchant_entity.ConvenienceStore:      true,
-	}
+	f.whereCategory(`m.category`, params.Category, merchantCategories)
 
-	if params.Category != "" {
-		if !validCategories[params.Category] {
-			return []*purchase_entity.GetUserOrder{}, nil
-		}
-		query += ` AND m.category = $` + strconv.Itoa(argId)
-		args = append(args, params.Category)
-		argId++
-	}
+	matches := f.clause()
 
-	query += ` LIMIT $` + strconv.Itoa(argId) + ` OFFSET $` + strconv.Itoa(argId+1)
-	args = append(args, params.Limit, params.Offset)
+	// the cursor selects the page of orders, not the rows they are made of
+	newest := keyset{columns: []string{"order_created_at", "order_id"}, descending: true}
+	f.predicates = nil
+	if params.Page.Cursor != nil {
+		f.where(newest.after(params.Page, f, params.Page.Cursor.CreatedAt, params.Page.Cursor.Id))
+	}
+	limit := pageLimit(params.Page, f)
 
-	rows, err := r.DB.Query(ctx, query, args...)
+	query := `
+		WITH matches AS (
+			SELECT
+				o.id AS order_id, o.created_at AS order_created_at, om.id AS order_merchant_id, oi.id AS order_item_id,
+				m.id AS merchant_id, m.name AS merchant_name, m.category AS merchant_category, m.image_url AS merchant_image_url,
+				ST_Y(m.location::geometry) AS latitude, ST_X(m.location::geometry) AS longitude, m.created_at AS merchant_created_at,
+				i.id AS item_id, i.name AS item_name, i.category AS item_category, i.price, oi.quantity,
+				i.image_url AS item_image_url, i.created_at AS item_created_at
+			FROM orders o
+			INNER JOIN order_merchants om ON om.estimate_id = o.estimate_id
+			INNER JOIN merchants m ON m.id = om.merchant_id
+			INNER JOIN order_items oi ON oi.order_merchant_id = om.id
+			INNER JOIN items i ON i.id = oi.item_id` + matches + `
+		),
+		page AS (
+			SELECT DISTINCT order_id, order_created_at
+			FROM matches` + f.clause() + `
+			ORDER BY ` + newest.orderBy(params.Page) + limit + `
+		)
+		SELECT
+			order_id, order_created_at,
+			merchant_id, merchant_name, merchant_category, merchant_image_url, latitude, longitude, merchant_created_at,
+			item_id, item_name, item_category, price, quantity, item_image_url, item_created_at
+		FROM matches
+		WHERE order_id IN (SELECT order_id FROM page)
+		ORDER BY ` + newest.orderBy(params.Page) + `, order_merchant_id, order_item_id`
+
+	rows, err := r.DB.Query(ctx, query, f.args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	orders := []*purchase_entity.UserOrderHistory{}
 	ordersMap := make(map[string]*purchase_entity.GetUserOrder)
 	for rows.Next() {
 		var (
 			orderId, merchantId, merchantName, merchantCategory, merchantImageUrl string
 			merchantLat, merchantLong                                             float64
-			merchantCreatedAt, itemCreatedAt                                      time.Time
+			orderCreatedAt, merchantCreatedAt, itemCreatedAt                      time.Time
 			itemId, itemName, itemCategory, itemImageUrl                          string
 			itemPrice, itemQuantity                                               int
 		)
 
 		err := rows.Scan(
-			&orderId,
+			&orderId, &orderCreatedAt,
 			&merchantId, &merchantName, &merchantCategory, &merchantImageUrl, &merchantLat, &merchantLong, &merchantCreatedAt,
 			&itemId, &itemName, &itemCategory, &itemPrice, &itemQuantity, &itemImageUrl, &itemCreatedAt,
 		)
@@ -372,6 +364,10 @@ func (r *PurchaseRepositoryImpl) GetOrders(ctx context.Context, userId string, p
 				OrderId: orderId,
 				Orders:  []purchase_entity.GetOrder{},
 			}
+			orders = append(orders, &purchase_entity.UserOrderHistory{
+				Order:    ordersMap[orderId],
+				Position: pagination_entity.Cursor{Id: orderId, CreatedAt: &orderCreatedAt},
+			})
 		}
 
 		order := ordersMap[orderId]
@@ -418,10 +414,142 @@ func (r *PurchaseRepositoryImpl) GetOrders(ctx context.Context, userId string, p
 		}
 	}
 
-	orders := make([]*purchase_entity.GetUserOrder, 0, len(ordersMap))
-	for _, order := range ordersMap {
-		orders = append(orders, order)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return orders, nil
 }
+
+func (r *PurchaseRepositoryImpl) GetEstimateState(ctx context.Context, estimateId string) (*purchase_entity.EstimateState, error) {
+	defer metrics.TimeQuery("PurchaseRepository", "GetEstimateState")()
+
+	var estimate purchase_entity.EstimateState
+	var timeCreated time.Time
+	query := `
+		SELECT
+			e.id, e.total_price, e.estimated_delivery_time,
+			EXISTS (SELECT 1 FROM orders o WHERE o.estimate_id = e.id),
+			e.voided_at IS NOT NULL,
+			e.created_at
+		FROM estimates e
+		WHERE e.id = $1
+	`
+	err := r.DB.QueryRow(ctx, query, estimateId).Scan(
+		&estimate.Id,
+		&estimate.TotalPrice,
+		&estimate.EstimatedDeliveryTime,
+		&estimate.IsOrdered,
+		&estimate.IsVoided,
+		&timeCreated,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, purchase_exception.ErrEstimateIdNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	estimate.CreatedAt = timeCreated.Format(time.RFC3339)
+	return &estimate, nil
+}
+
+// RepriceEstimate recalculates every total of an open estimate from the
+// current item prices.
+func (r *PurchaseRepositoryImpl) RepriceEstimate(ctx context.Context, estimateId string) (totalPrice int, err error) {
+	defer metrics.TimeQuery("PurchaseRepository", "RepriceEstimate")()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the estimate so it can't be ordered or voided halfway through
+	if err = lockModifiableEstimate(ctx, tx, estimateId); err != nil {
+		return 0, err
+	}
+
+	repriceItemsQuery := `
+		UPDATE order_items oi
+		SET total_item_price = oi.quantity * i.price, updated_at = NOW()
+		FROM items i, order_merchants om
+		WHERE i.id = oi.item_id AND om.id = oi.order_merchant_id AND om.estimate_id = $1
+	`
+	if _, err = tx.Exec(ctx, repriceItemsQuery, estimateId); err != nil {
+		return 0, err
+	}
+
+	repriceMerchantsQuery := `
+		UPDATE order_merchants om
+		SET total_merchant_price = (
+			SELECT COALESCE(SUM(total_item_price), 0)
+			FROM order_items
+			WHERE order_merchant_id = om.id
+		), updated_at = NOW()
+		WHERE om.estimate_id = $1
+	`
+	if _, err = tx.Exec(ctx, repriceMerchantsQuery, estimateId); err != nil {
+		return 0, err
+	}
+
+	repriceEstimateQuery := `
+		UPDATE estimates
+		SET total_price = (
+			SELECT COALESCE(SUM(total_merchant_price), 0)
+			FROM order_merchants
+			WHERE estimate_id = $1
+		), updated_at = NOW()
+		WHERE id = $1
+		RETURNING total_price
+	`
+	if err = tx.QueryRow(ctx, repriceEstimateQuery, estimateId).Scan(&totalPrice); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return totalPrice, nil
+}
+
+func (r *PurchaseRepositoryImpl) VoidEstimate(ctx context.Context, estimateId string) error {
+	defer metrics.TimeQuery("PurchaseRepository", "VoidEstimate")()
+
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		if err := lockModifiableEstimate(ctx, tx, estimateId); err != nil {
+			return err
+		}
+
+		query := `UPDATE estimates SET voided_at = NOW(), updated_at = NOW() WHERE id = $1`
+		_, err := tx.Exec(ctx, query, estimateId)
+		return err
+	})
+}
+
+// lockModifiableEstimate locks an estimate for the rest of the transaction
+// and reports why it can no longer be changed, if it was voided or ordered
+// since the caller last looked at it.
+func lockModifiableEstimate(ctx context.Context, tx pgx.Tx, estimateId string) error {
+	var isVoided, isOrdered bool
+	query := `
+		SELECT e.voided_at IS NOT NULL,
+			EXISTS (SELECT 1 FROM orders o WHERE o.estimate_id = e.id)
+		FROM estimates e
+		WHERE e.id = $1
+		FOR UPDATE
+	`
+	err := tx.QueryRow(ctx, query, estimateId).Scan(&isVoided, &isOrdered)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return purchase_exception.ErrEstimateIdNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if isVoided {
+		return purchase_exception.ErrEstimateVoided
+	}
+	if isOrdered {
+		return purchase_exception.ErrEstimateOrdered
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	search_entity "github.com/danzBraham/beli-mang/internal/entities/search"
+	"github.com/danzBraham/beli-mang/internal/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SearchRepository interface {
+	SearchMerchants(ctx context.Context, params *search_entity.SearchQueryParams) ([]*search_entity.MerchantMatch, int, error)
+	SearchItems(ctx context.Context, query string, merchantIds []string, limit int) (map[string][]*search_entity.ItemMatch, error)
+}
+
+type SearchRepositoryImpl struct {
+	DB *pgxpool.Pool
+}
+
+func NewSearchRepository(db *pgxpool.Pool) SearchRepository {
+	return &SearchRepositoryImpl{DB: db}
+}
+
+// ts_headline wraps the matched words in these markers rather than in tags.
+// They are stripped from the name first, so once the name is escaped they are
+// the only thing turned into markup.
+const (
+	highlightStartMarker = "\x01"
+	highlightStopMarker  = "\x02"
+)
+
+var highlightMarkers = strings.NewReplacer(
+	highlightStartMarker, search_entity.HighlightStart,
+	highlightStopMarker, search_entity.HighlightStop,
+)
+
+// searchTerms parses $1 once for the queries below. A row matches when its
+// search vector matches the words or, to forgive typos, when the words are
+// similar enough to part of its name (pg_trgm's <% operator). Both are served
+// by GIN indexes.
+const searchTerms = `search AS (
+							SELECT websearch_to_tsquery('simple', $1) AS query, $1::text AS term
+						)`
+
+// merchantMatches ranks every merchant matching $1, weighting the merchants
+// that only match by item with $2.
+const merchantMatches = `merchant_matches AS (
+							SELECT m.id, ts_rank_cd(m.search, s.query) + word_similarity(s.term, m.name) AS rank
+							FROM merchants m, search s
+							WHERE m.search @@ s.query OR s.term <% m.name
+						),
+						item_matches AS (
+							SELECT i.merchant_id AS id, MAX(ts_rank_cd(i.search, s.query) + word_similarity(s.term, i.name)) AS rank
+							FROM items i, search s
+							WHERE i.search @@ s.query OR s.term <% i.name
+							GROUP BY i.merchant_id
+						),
+						matches AS (
+							SELECT id, MAX(rank)::float8 AS relevance
+							FROM (
+								SELECT id, rank FROM merchant_matches
+								UNION ALL
+								SELECT id, rank * $2 FROM item_matches
+							) ranks
+							GROUP BY id
+						)`
+
+// SearchMerchants ranks the merchants matching the query by name or category,
+// or selling an item matching it, and returns a page of them with the number
+// of matches. With a location the relevance decays with distance.
+func (r *SearchRepositoryImpl) SearchMerchants(ctx context.Context, params *search_entity.SearchQueryParams) ([]*search_entity.MerchantMatch, int, error) {
+	defer metrics.TimeQuery("SearchRepository", "SearchMerchants")()
+
+	query := `WITH ` + searchTerms + `, ` + merchantMatches + `
+						SELECT
+							m.id, m.name, m.category, m.image_url,
+							ST_Y(m.location::geometry) AS latitude, ST_X(m.location::geometry) AS longitude, m.created_at,
+							x.relevance, d.distance_km,
+							x.relevance / (1 + COALESCE(d.distance_km, 0) / $3) AS score,
+							COUNT(*) OVER () AS total
+						FROM matches x
+						JOIN merchants m ON m.id = x.id`
+	f := &filter{args: []interface{}{params.Query, search_entity.ItemMatchWeight, search_entity.DistanceDecayKm}}
+
+	if params.Location != nil {
+		query += `
+						CROSS JOIN LATERAL (
+							SELECT ST_Distance(m.location, ST_SetSRID(ST_MakePoint(` + f.bind(params.Location.Long) + `, ` + f.bind(params.Location.Lat) + `), 4326)::geography) / 1000 AS distance_km
+						) d`
+	} else {
+		query += `
+						CROSS JOIN LATERAL (SELECT NULL::float8 AS distance_km) d`
+	}
+
+	f.whereCategory(`m.category`, params.Category, merchantCategories)
+
+	query += f.clause() + ` ORDER BY score DESC, m.id`
+	query += ` LIMIT ` + f.bind(params.Limit) + ` OFFSET ` + f.bind(params.Offset)
+
+	rows, err := r.DB.Query(ctx, query, f.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	matches := []*search_entity.MerchantMatch{}
+	for rows.Next() {
+		var merchant merchant_entity.GetMerchant
+		var match search_entity.MerchantMatch
+		var timeCreated time.Time
+		err := rows.Scan(
+			&merchant.Id,
+			&merchant.Name,
+			&merchant.Category,
+			&merchant.ImageURL,
+			&merchant.Location.Lat,
+			&merchant.Location.Long,
+			&timeCreated,
+			&match.Relevance,
+			&match.DistanceKm,
+			&match.Score,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		merchant.CreatedAt = timeCreated.Format(time.RFC3339)
+		match.Merchant = &merchant
+		matches = append(matches, &match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// past the last match there's no row to carry the total
+	if len(matches) == 0 && params.Offset > 0 {
+		total, err = r.countMerchantMatches(ctx, params)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return matches, total, nil
+}
+
+func (r *SearchRepositoryImpl) countMerchantMatches(ctx context.Context, params *search_entity.SearchQueryParams) (int, error) {
+	defer metrics.TimeQuery("SearchRepository", "CountMerchantMatches")()
+
+	f := &filter{args: []interface{}{params.Query, search_entity.ItemMatchWeight}}
+	f.whereCategory(`m.category`, params.Category, merchantCategories)
+
+	query := `WITH ` + searchTerms + `, ` + merchantMatches + `
+						SELECT COUNT(*)
+						FROM matches x
+						JOIN merchants m ON m.id = x.id` + f.clause()
+
+	var total int
+	err := r.DB.QueryRow(ctx, query, f.args...).Scan(&total)
+	return total, err
+}
+
+// SearchItems returns up to limit items per merchant matching the query, the
+// best match first, with the matching words highlighted.
+func (r *SearchRepositoryImpl) SearchItems(ctx context.Context, query string, merchantIds []string, limit int) (map[string][]*search_entity.ItemMatch, error) {
+	defer metrics.TimeQuery("SearchRepository", "SearchItems")()
+
+	itemsByMerchant := make(map[string][]*search_entity.ItemMatch, len(merchantIds))
+	if len(merchantIds) == 0 || limit == 0 {
+		return itemsByMerchant, nil
+	}
+
+	sql := `WITH ` + searchTerms + `
+					SELECT id, name, category, price, image_url, merchant_id, created_at, updated_at, highlight
+					FROM (
+						SELECT
+							i.id, i.name, i.category, i.price, i.image_url, i.merchant_id, i.created_at, i.updated_at,
+							ts_headline('simple', translate(i.name, $5, ''), s.query, $4) AS highlight,
+							ROW_NUMBER() OVER (
+								PARTITION BY i.merchant_id
+								ORDER BY ts_rank_cd(i.search, s.query) + word_similarity(s.term, i.name) DESC, i.id
+							) AS rank
+						FROM items i, search s
+						WHERE i.merchant_id = ANY($2) AND (i.search @@ s.query OR s.term <% i.name)
+					) ranked
+					WHERE rank <= $3
+					ORDER BY merchant_id, rank`
+	options := `StartSel="` + highlightStartMarker + `", StopSel="` + highlightStopMarker + `", HighlightAll=true`
+
+	rows, err := r.DB.Query(ctx, sql, query, merchantIds, limit, options, highlightStartMarker+highlightStopMarker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item item_entity.Item
+		var match search_entity.ItemMatch
+		var timeCreated, timeUpdated time.Time
+		err := rows.Scan(
+			&item.Id,
+			&item.Name,
+			&item.Category,
+			&item.Price,
+			&item.ImageURL,
+			&item.MerchantId,
+			&timeCreated,
+			&timeUpdated,
+			&match.Highlight,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.CreatedAt = timeCreated.Format(time.RFC3339)
+		item.UpdatedAt = timeUpdated.Format(time.RFC3339)
+		match.Highlight = highlightMarkers.Replace(html.EscapeString(match.Highlight))
+		match.Item = &item
+		itemsByMerchant[item.MerchantId] = append(itemsByMerchant[item.MerchantId], &match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return itemsByMerchant, nil
+}
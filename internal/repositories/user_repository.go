@@ -3,9 +3,11 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
 	user_entity "github.com/danzBraham/beli-mang/internal/entities/user"
 	user_exception "github.com/danzBraham/beli-mang/internal/exceptions/user"
+	"github.com/danzBraham/beli-mang/internal/metrics"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -14,9 +16,15 @@ type UserRepository interface {
 	VerifyUsername(ctx context.Context, username string) (bool, error)
 	VerifyAdminEmail(ctx context.Context, email string) (bool, error)
 	VerifyUserEmail(ctx context.Context, email string) (bool, error)
+	VerifyUserActive(ctx context.Context, userId string) (bool, error)
 	CreateUser(ctx context.Context, user *user_entity.User) error
 	GetAdminUserByUsername(ctx context.Context, username string) (*user_entity.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*user_entity.User, error)
+	FindUserByUsername(ctx context.Context, username string) (*user_entity.User, error)
+	GetUsers(ctx context.Context, params *user_entity.UserQueryParams) ([]*user_entity.User, error)
+	UpdatePassword(ctx context.Context, userId, password string) error
+	UpdateIsAdmin(ctx context.Context, userId string, isAdmin bool) error
+	UpdateIsDisabled(ctx context.Context, userId string, isDisabled bool) error
 }
 
 type UserRepositoryImpl struct {
@@ -28,6 +36,8 @@ func NewUserRepository(db *pgxpool.Pool) UserRepository {
 }
 
 func (r *UserRepositoryImpl) VerifyUsername(ctx context.Context, username string) (bool, error) {
+	defer metrics.TimeQuery("UserRepository", "VerifyUsername")()
+
 	var one int
 	query := `SELECT 1 FROM users WHERE username = $1`
 	err := r.DB.QueryRow(ctx, query, username).Scan(&one)
@@ -41,6 +51,8 @@ func (r *UserRepositoryImpl) VerifyUsername(ctx context.Context, username string
 }
 
 func (r *UserRepositoryImpl) VerifyAdminEmail(ctx context.Context, email string) (bool, error) {
+	defer metrics.TimeQuery("UserRepository", "VerifyAdminEmail")()
+
 	var one int
 	query := `SELECT 1 FROM users WHERE email = $1 AND is_admin = true`
 	err := r.DB.QueryRow(ctx, query, email).Scan(&one)
@@ -54,6 +66,8 @@ func (r *UserRepositoryImpl) VerifyAdminEmail(ctx context.Context, email string)
 }
 
 func (r *UserRepositoryImpl) VerifyUserEmail(ctx context.Context, email string) (bool, error) {
+	defer metrics.TimeQuery("UserRepository", "VerifyUserEmail")()
+
 	var one int
 	query := `SELECT 1 FROM users WHERE email = $1 AND is_admin = false`
 	err := r.DB.QueryRow(ctx, query, email).Scan(&one)
@@ -66,7 +80,24 @@ func (r *UserRepositoryImpl) VerifyUserEmail(ctx context.Context, email string)
 	return true, nil
 }
 
+func (r *UserRepositoryImpl) VerifyUserActive(ctx context.Context, userId string) (bool, error) {
+	defer metrics.TimeQuery("UserRepository", "VerifyUserActive")()
+
+	var isActive bool
+	query := `SELECT disabled_at IS NULL FROM users WHERE id = $1`
+	err := r.DB.QueryRow(ctx, query, userId).Scan(&isActive)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, user_exception.ErrUserNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	return isActive, nil
+}
+
 func (r *UserRepositoryImpl) CreateUser(ctx context.Context, user *user_entity.User) error {
+	defer metrics.TimeQuery("UserRepository", "CreateUser")()
+
 	query := `INSERT INTO users (id, username, password, email, is_admin)
 						VALUES ($1, $2, $3, $4, $5)`
 	_, err := r.DB.Exec(ctx, query, &user.Id, &user.Username, &user.Password, &user.Email, &user.IsAdmin)
@@ -77,9 +108,11 @@ func (r *UserRepositoryImpl) CreateUser(ctx context.Context, user *user_entity.U
 }
 
 func (r *UserRepositoryImpl) GetAdminUserByUsername(ctx context.Context, username string) (*user_entity.User, error) {
+	defer metrics.TimeQuery("UserRepository", "GetAdminUserByUsername")()
+
 	var user user_entity.User
-	query := `SELECT id, username, password, email, is_admin FROM users WHERE username = $1 AND is_admin = true`
-	err := r.DB.QueryRow(ctx, query, username).Scan(&user.Id, &user.Username, &user.Password, &user.Email, &user.IsAdmin)
+	query := `SELECT id, username, password, email, is_admin, disabled_at IS NOT NULL FROM users WHERE username = $1 AND is_admin = true`
+	err := r.DB.QueryRow(ctx, query, username).Scan(&user.Id, &user.Username, &user.Password, &user.Email, &user.IsAdmin, &user.IsDisabled)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, user_exception.ErrUserNotFound
 	}
@@ -90,14 +123,111 @@ func (r *UserRepositoryImpl) GetAdminUserByUsername(ctx context.Context, usernam
 }
 
 func (r *UserRepositoryImpl) GetUserByUsername(ctx context.Context, username string) (*user_entity.User, error) {
+	defer metrics.TimeQuery("UserRepository", "GetUserByUsername")()
+
+	var user user_entity.User
+	query := `SELECT id, username, password, email, is_admin, disabled_at IS NOT NULL FROM users WHERE username = $1 AND is_admin = false`
+	err := r.DB.QueryRow(ctx, query, username).Scan(&user.Id, &user.Username, &user.Password, &user.Email, &user.IsAdmin, &user.IsDisabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, user_exception.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) FindUserByUsername(ctx context.Context, username string) (*user_entity.User, error) {
+	defer metrics.TimeQuery("UserRepository", "FindUserByUsername")()
+
 	var user user_entity.User
-	query := `SELECT id, username, password, email, is_admin FROM users WHERE username = $1 AND is_admin = false`
-	err := r.DB.QueryRow(ctx, query, username).Scan(&user.Id, &user.Username, &user.Password, &user.Email, &user.IsAdmin)
+	var timeCreated time.Time
+	query := `SELECT id, username, password, email, is_admin, disabled_at IS NOT NULL, created_at FROM users WHERE username = $1`
+	err := r.DB.QueryRow(ctx, query, username).Scan(&user.Id, &user.Username, &user.Password, &user.Email, &user.IsAdmin, &user.IsDisabled, &timeCreated)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, user_exception.ErrUserNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
+	user.CreatedAt = timeCreated.Format(time.RFC3339)
 	return &user, nil
 }
+
+func (r *UserRepositoryImpl) GetUsers(ctx context.Context, params *user_entity.UserQueryParams) ([]*user_entity.User, error) {
+	defer metrics.TimeQuery("UserRepository", "GetUsers")()
+
+	f := &filter{}
+
+	if params.Username != "" {
+		f.where(`username ILIKE ` + f.bind("%"+params.Username+"%"))
+	}
+
+	switch params.Role {
+	case "admin":
+		f.where(`is_admin = true`)
+	case "user":
+		f.where(`is_admin = false`)
+	}
+
+	query := `SELECT id, username, email, is_admin, disabled_at IS NOT NULL, created_at FROM users` + f.clause()
+	query += ` ORDER BY created_at DESC, id`
+	query += ` LIMIT ` + f.bind(params.Limit) + ` OFFSET ` + f.bind(params.Offset)
+
+	rows, err := r.DB.Query(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*user_entity.User{}
+	for rows.Next() {
+		var user user_entity.User
+		var timeCreated time.Time
+		err := rows.Scan(&user.Id, &user.Username, &user.Email, &user.IsAdmin, &user.IsDisabled, &timeCreated)
+		if err != nil {
+			return nil, err
+		}
+		user.CreatedAt = timeCreated.Format(time.RFC3339)
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, userId, password string) error {
+	defer metrics.TimeQuery("UserRepository", "UpdatePassword")()
+
+	query := `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`
+	return r.exec(ctx, query, password, userId)
+}
+
+func (r *UserRepositoryImpl) UpdateIsAdmin(ctx context.Context, userId string, isAdmin bool) error {
+	defer metrics.TimeQuery("UserRepository", "UpdateIsAdmin")()
+
+	query := `UPDATE users SET is_admin = $1, updated_at = NOW() WHERE id = $2`
+	return r.exec(ctx, query, isAdmin, userId)
+}
+
+func (r *UserRepositoryImpl) UpdateIsDisabled(ctx context.Context, userId string, isDisabled bool) error {
+	defer metrics.TimeQuery("UserRepository", "UpdateIsDisabled")()
+
+	query := `UPDATE users SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, NOW()) END, updated_at = NOW() WHERE id = $2`
+	return r.exec(ctx, query, isDisabled, userId)
+}
+
+// exec runs an update against a single user and reports a missing user.
+func (r *UserRepositoryImpl) exec(ctx context.Context, query string, args ...interface{}) error {
+	tag, err := r.DB.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return user_exception.ErrUserNotFound
+	}
+	return nil
+}
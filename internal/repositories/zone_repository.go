@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+
+	zone_entity "github.com/danzBraham/beli-mang/internal/entities/zone"
+	"github.com/danzBraham/beli-mang/internal/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ZoneRepository interface {
+	GetZones(ctx context.Context, params *zone_entity.ZoneQueryParams, limit int) ([]*zone_entity.Zone, error)
+}
+
+type ZoneRepositoryImpl struct {
+	DB *pgxpool.Pool
+}
+
+func NewZoneRepository(db *pgxpool.Pool) ZoneRepository {
+	return &ZoneRepositoryImpl{DB: db}
+}
+
+// GetZones groups merchants and the delivery locations of placed orders by
+// geohash cell. The bbox is matched with inBBox so the GIST indexes on the
+// locations are used.
+func (r *ZoneRepositoryImpl) GetZones(ctx context.Context, params *zone_entity.ZoneQueryParams, limit int) ([]*zone_entity.Zone, error) {
+	defer metrics.TimeQuery("ZoneRepository", "GetZones")()
+
+	// the two CTEs share one argument list, so f binds every argument while
+	// merchants and orders only collect the predicates of their own CTE
+	f := &filter{}
+	precision := f.bind(params.Precision)
+	merchants, orders := &filter{}, &filter{}
+
+	if params.BBox != nil {
+		envelope := f.envelope(params.BBox)
+		merchants.where(inBBox(`location`, envelope))
+		orders.where(inBBox(`e.user_location`, envelope))
+	}
+
+	orders.where(`e.voided_at IS NULL`)
+	if params.From != nil {
+		orders.where(`o.created_at >= ` + f.bind(*params.From))
+	}
+	if params.To != nil {
+		orders.where(`o.created_at < ` + f.bind(*params.To))
+	}
+
+	query := `
+		WITH merchant_cells AS (
+			SELECT ST_GeoHash(location::geometry, ` + precision + `) AS geohash, COUNT(*) AS merchants
+			FROM merchants` + merchants.clause() + `
+			GROUP BY 1
+		),
+		order_cells AS (
+			SELECT
+				ST_GeoHash(e.user_location::geometry, ` + precision + `) AS geohash,
+				COUNT(*) AS orders,
+				AVG(e.total_price)::float8 AS average_basket,
+				(AVG(EXTRACT(EPOCH FROM o.delivered_at - o.created_at)) / 60)::float8 AS average_delivery_time,
+				AVG(e.estimated_delivery_time)::float8 AS average_estimated_delivery_time
+			FROM orders o
+			JOIN estimates e ON e.id = o.estimate_id` + orders.clause() + `
+			GROUP BY 1
+		)
+		SELECT
+			cells.geohash,
+			ST_AsGeoJSON(ST_SetSRID(ST_Box2dFromGeoHash(cells.geohash)::geometry, 4326), 6),
+			COALESCE(m.merchants, 0),
+			COALESCE(oc.orders, 0),
+			oc.average_basket,
+			oc.average_delivery_time,
+			oc.average_estimated_delivery_time
+		FROM (
+			SELECT geohash FROM merchant_cells
+			UNION
+			SELECT geohash FROM order_cells
+		) cells
+		LEFT JOIN merchant_cells m ON m.geohash = cells.geohash
+		LEFT JOIN order_cells oc ON oc.geohash = cells.geohash
+		ORDER BY cells.geohash
+		LIMIT ` + f.bind(limit)
+
+	rows, err := r.DB.Query(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := []*zone_entity.Zone{}
+	for rows.Next() {
+		var zone zone_entity.Zone
+		err := rows.Scan(
+			&zone.Geohash,
+			&zone.Geometry,
+			&zone.Merchants,
+			&zone.Orders,
+			&zone.AverageBasket,
+			&zone.AverageDeliveryTime,
+			&zone.AverageEstimatedDeliveryTime,
+		)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, &zone)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
@@ -0,0 +1,283 @@
+package routing
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	formula_helper "github.com/danzBraham/beli-mang/internal/helpers/formula"
+	"github.com/danzBraham/beli-mang/internal/tracing"
+)
+
+// cellSize is the side, in degrees, of the grid cells nodes are indexed in.
+const cellSize = 0.01
+
+// connectorSpeedKmh is the speed assumed between a location and the road
+// node it is snapped to, for example through a parking lot or an alley.
+const connectorSpeedKmh = 10
+
+// A search gives up with ErrNoRoute once it has settled maxSettledNodes nodes
+// or every route left would take longer than maxDetourFactor times the
+// straight line at the network's top speed plus maxDetourSlack. Without them
+// a target cut off from the source, such as a merchant snapped onto an
+// isolated service road, is only found unreachable after exploring every
+// road the source reaches.
+const (
+	maxSettledNodes = 250_000
+	maxDetourFactor = 4
+	maxDetourSlack  = 10 * time.Minute
+)
+
+type cell struct {
+	lat, long int32
+}
+
+// Graph is a directed road network in compressed sparse row form: the edges
+// leaving node n are targets[offsets[n]:offsets[n+1]]. It is read only once
+// built and safe for concurrent use.
+type Graph struct {
+	lats, longs []float64
+	offsets     []int32
+	targets     []int32
+	lengths     []float32 // meters
+	durations   []float32 // seconds
+	cells       map[cell][]int32
+	// maxSpeed is the fastest edge speed in meters per second, it keeps the
+	// A* heuristic admissible
+	maxSpeed      float64
+	maxSnapDistKm float64
+}
+
+// Nodes returns the number of road nodes in the graph.
+func (g *Graph) Nodes() int {
+	return len(g.lats)
+}
+
+// Edges returns the number of directed road segments in the graph.
+func (g *Graph) Edges() int {
+	return len(g.targets)
+}
+
+func (g *Graph) location(n int32) purchase_entity.Location {
+	return purchase_entity.Location{Lat: g.lats[n], Long: g.longs[n]}
+}
+
+func cellOf(lat, long float64) cell {
+	return cell{lat: int32(math.Floor(lat / cellSize)), long: int32(math.Floor(long / cellSize))}
+}
+
+// nearest returns the node closest to p within the snap distance.
+func (g *Graph) nearest(p purchase_entity.Location) (int32, float64, bool) {
+	// one degree of latitude is about 111.2 km, a degree of longitude shrinks
+	// with the cosine of the latitude
+	latCells := int32(math.Ceil(g.maxSnapDistKm / (111.2 * cellSize)))
+	longCells := latCells
+	if cos := math.Cos(p.Lat * math.Pi / 180); cos > 0.01 {
+		longCells = int32(math.Ceil(g.maxSnapDistKm / (111.2 * cellSize * cos)))
+	}
+
+	center := cellOf(p.Lat, p.Long)
+	best, bestDistance := int32(-1), math.Inf(1)
+	for dLat := -latCells; dLat <= latCells; dLat++ {
+		for dLong := -longCells; dLong <= longCells; dLong++ {
+			for _, n := range g.cells[cell{lat: center.lat + dLat, long: center.long + dLong}] {
+				if distance := formula_helper.Distance(p, g.location(n)); distance < bestDistance {
+					best, bestDistance = n, distance
+				}
+			}
+		}
+	}
+
+	if best < 0 || bestDistance > g.maxSnapDistKm {
+		return 0, 0, false
+	}
+	return best, bestDistance, true
+}
+
+// Route finds the fastest path with A*, using the straight line distance at
+// the network's top speed as the heuristic.
+func (g *Graph) Route(ctx context.Context, from, to purchase_entity.Location) (Route, error) {
+	ctx, span := tracing.Start(ctx, "Graph.Route")
+	defer span.End()
+
+	source, sourceSnapKm, ok := g.nearest(from)
+	if !ok {
+		return Route{}, ErrOutsideNetwork
+	}
+	target, targetSnapKm, ok := g.nearest(to)
+	if !ok {
+		return Route{}, ErrOutsideNetwork
+	}
+
+	seconds, meters, err := g.search(ctx, source, target)
+	if err != nil {
+		return Route{}, err
+	}
+
+	snapKm := sourceSnapKm + targetSnapKm
+	seconds += snapKm / connectorSpeedKmh * 3600
+	return Route{
+		DistanceKm: meters/1000 + snapKm,
+		Duration:   time.Duration(seconds * float64(time.Second)),
+	}, nil
+}
+
+// search returns the travel time and length of the fastest path.
+func (g *Graph) search(ctx context.Context, source, target int32) (seconds, meters float64, err error) {
+	goal := g.location(target)
+	heuristic := func(n int32) float64 {
+		return formula_helper.Distance(g.location(n), goal) * 1000 / g.maxSpeed
+	}
+
+	// only the visited nodes are tracked, a city graph has millions of nodes
+	// and a delivery route touches a few thousand
+	type label struct {
+		seconds, meters float64
+		done            bool
+	}
+	labels := map[int32]*label{source: {}}
+	queue := &nodeQueue{{node: source, priority: heuristic(source)}}
+	maxSeconds := heuristic(source)*maxDetourFactor + maxDetourSlack.Seconds()
+	settled := 0
+
+	for steps := 0; queue.Len() > 0; steps++ {
+		if steps%1024 == 0 && ctx.Err() != nil {
+			return 0, 0, ctx.Err()
+		}
+
+		// the priority never overestimates, so once the best one is over the
+		// limit every route left is too
+		item := heap.Pop(queue).(queued)
+		if item.priority > maxSeconds {
+			break
+		}
+		current := item.node
+		currentLabel := labels[current]
+		if currentLabel.done {
+			continue
+		}
+		if current == target {
+			return currentLabel.seconds, currentLabel.meters, nil
+		}
+		currentLabel.done = true
+		if settled++; settled > maxSettledNodes {
+			break
+		}
+
+		for e := g.offsets[current]; e < g.offsets[current+1]; e++ {
+			next := g.targets[e]
+			seconds := currentLabel.seconds + float64(g.durations[e])
+			nextLabel, seen := labels[next]
+			if seen && (nextLabel.done || nextLabel.seconds <= seconds) {
+				continue
+			}
+			if !seen {
+				nextLabel = &label{}
+				labels[next] = nextLabel
+			}
+			nextLabel.seconds = seconds
+			nextLabel.meters = currentLabel.meters + float64(g.lengths[e])
+			heap.Push(queue, queued{node: next, priority: seconds + heuristic(next)})
+		}
+	}
+
+	return 0, 0, ErrNoRoute
+}
+
+type queued struct {
+	node     int32
+	priority float64
+}
+
+type nodeQueue []queued
+
+func (q nodeQueue) Len() int           { return len(q) }
+func (q nodeQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q nodeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x any)        { *q = append(*q, x.(queued)) }
+
+func (q *nodeQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// edge is a directed road segment collected while building a Graph.
+type edge struct {
+	from, to int32
+	meters   float32
+	seconds  float32
+}
+
+// graphBuilder collects nodes and edges in any order and packs them.
+type graphBuilder struct {
+	lats, longs []float64
+	edges       []edge
+	maxSpeed    float64
+}
+
+func (b *graphBuilder) addNode(lat, long float64) int32 {
+	b.lats = append(b.lats, lat)
+	b.longs = append(b.longs, long)
+	return int32(len(b.lats) - 1)
+}
+
+// addRoad adds the segment between two nodes in the allowed directions.
+func (b *graphBuilder) addRoad(from, to int32, speedKmh float64, forward, backward bool) {
+	meters := formula_helper.Distance(
+		purchase_entity.Location{Lat: b.lats[from], Long: b.longs[from]},
+		purchase_entity.Location{Lat: b.lats[to], Long: b.longs[to]},
+	) * 1000
+	speed := speedKmh / 3.6
+	seconds := float32(meters / speed)
+	b.maxSpeed = math.Max(b.maxSpeed, speed)
+
+	if forward {
+		b.edges = append(b.edges, edge{from: from, to: to, meters: float32(meters), seconds: seconds})
+	}
+	if backward {
+		b.edges = append(b.edges, edge{from: to, to: from, meters: float32(meters), seconds: seconds})
+	}
+}
+
+func (b *graphBuilder) build(maxSnapDistanceM float64) *Graph {
+	g := &Graph{
+		lats:          b.lats,
+		longs:         b.longs,
+		offsets:       make([]int32, len(b.lats)+1),
+		targets:       make([]int32, len(b.edges)),
+		lengths:       make([]float32, len(b.edges)),
+		durations:     make([]float32, len(b.edges)),
+		cells:         map[cell][]int32{},
+		maxSpeed:      b.maxSpeed,
+		maxSnapDistKm: maxSnapDistanceM / 1000,
+	}
+
+	// counting sort of the edges by their source node
+	for _, e := range b.edges {
+		g.offsets[e.from+1]++
+	}
+	for n := 1; n < len(g.offsets); n++ {
+		g.offsets[n] += g.offsets[n-1]
+	}
+	next := append([]int32(nil), g.offsets[:len(b.lats)]...)
+	connected := make([]bool, len(b.lats))
+	for _, e := range b.edges {
+		i := next[e.from]
+		next[e.from]++
+		g.targets[i], g.lengths[i], g.durations[i] = e.to, e.meters, e.seconds
+		connected[e.from], connected[e.to] = true, true
+	}
+
+	for n := range b.lats {
+		if connected[n] {
+			key := cellOf(b.lats[n], b.longs[n])
+			g.cells[key] = append(g.cells[key], int32(n))
+		}
+	}
+
+	return g
+}
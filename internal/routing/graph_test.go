@@ -0,0 +1,189 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+// origin anchors the synthetic networks below, in central Jakarta.
+var origin = purchase_entity.Location{Lat: -6.2, Long: 106.8}
+
+// at returns the point north and east meters away from origin.
+func at(north, east float64) purchase_entity.Location {
+	const metersPerDegree = 111_195
+	return purchase_entity.Location{
+		Lat:  origin.Lat + north/metersPerDegree,
+		Long: origin.Long + east/(metersPerDegree*math.Cos(origin.Lat*math.Pi/180)),
+	}
+}
+
+type testRoad struct {
+	from, to          int32
+	speedKmh          float64
+	forward, backward bool
+}
+
+// newTestGraph builds a network of the given nodes and roads, snapping
+// locations up to 50 m away.
+func newTestGraph(nodes []purchase_entity.Location, roads []testRoad) *Graph {
+	builder := &graphBuilder{}
+	for _, n := range nodes {
+		builder.addNode(n.Lat, n.Long)
+	}
+	for _, r := range roads {
+		builder.addRoad(r.from, r.to, r.speedKmh, r.forward, r.backward)
+	}
+	return builder.build(50)
+}
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestGraphRouteFollowsOneWayRoads(t *testing.T) {
+	// a one-way street from a to b, the way back goes around through c
+	a, b, c := at(0, 0), at(0, 200), at(200, 100)
+	g := newTestGraph([]purchase_entity.Location{a, b, c}, []testRoad{
+		{from: 0, to: 1, speedKmh: 25, forward: true},
+		{from: 1, to: 2, speedKmh: 25, forward: true, backward: true},
+		{from: 2, to: 0, speedKmh: 25, forward: true, backward: true},
+	})
+
+	there, err := g.Route(context.Background(), a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(there.DistanceKm, 0.2, 0.001) {
+		t.Errorf("a to b is %.3f km, want the 0.2 km one-way street", there.DistanceKm)
+	}
+
+	back, err := g.Route(context.Background(), b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two legs of sqrt(200² + 100²) m around through c
+	if !near(back.DistanceKm, 2*math.Hypot(200, 100)/1000, 0.001) {
+		t.Errorf("b to a is %.3f km, want the %.3f km detour", back.DistanceKm, 2*math.Hypot(200, 100)/1000)
+	}
+	if back.Duration <= there.Duration {
+		t.Errorf("b to a takes %v, want longer than a to b's %v", back.Duration, there.Duration)
+	}
+}
+
+func TestGraphRoutePrefersFasterRoadsOverTheStraightLine(t *testing.T) {
+	// a slow lane straight from a to b and a main road around through c
+	a, b, c := at(0, 0), at(0, 1000), at(500, 500)
+	g := newTestGraph([]purchase_entity.Location{a, b, c}, []testRoad{
+		{from: 0, to: 1, speedKmh: 10, forward: true, backward: true},
+		{from: 0, to: 2, speedKmh: 45, forward: true, backward: true},
+		{from: 2, to: 1, speedKmh: 45, forward: true, backward: true},
+	})
+
+	route, err := g.Route(context.Background(), a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detourKm := 2 * math.Hypot(500, 500) / 1000
+	if !near(route.DistanceKm, detourKm, 0.001) {
+		t.Errorf("route is %.3f km, want the %.3f km main road", route.DistanceKm, detourKm)
+	}
+	wantSeconds := detourKm / 45 * 3600
+	if !near(route.Duration.Seconds(), wantSeconds, 0.5) {
+		t.Errorf("route takes %.1fs, want %.1fs", route.Duration.Seconds(), wantSeconds)
+	}
+}
+
+func TestGraphRouteWithoutPath(t *testing.T) {
+	cases := []struct {
+		name  string
+		nodes []purchase_entity.Location
+		roads []testRoad
+	}{
+		{
+			name:  "disconnected",
+			nodes: []purchase_entity.Location{at(0, 0), at(0, 300), at(1000, 0), at(1000, 300)},
+			roads: []testRoad{
+				{from: 0, to: 1, speedKmh: 25, forward: true, backward: true},
+				{from: 2, to: 3, speedKmh: 25, forward: true, backward: true},
+			},
+		},
+		{
+			// every street at the target only leads away from it
+			name:  "against one-way streets",
+			nodes: []purchase_entity.Location{at(0, 0), at(1000, 0), at(0, 300)},
+			roads: []testRoad{
+				{from: 0, to: 1, speedKmh: 25, forward: true, backward: true},
+				{from: 2, to: 0, speedKmh: 25, forward: true},
+				{from: 2, to: 1, speedKmh: 25, forward: true},
+			},
+		},
+		{
+			// the only way round is 10 km for 100 m, past the detour cutoff
+			name:  "past the detour cutoff",
+			nodes: []purchase_entity.Location{at(0, 0), at(1000, 0), at(5000, 50), at(0, 100)},
+			roads: []testRoad{
+				{from: 0, to: 1, speedKmh: 45, forward: true, backward: true},
+				{from: 0, to: 2, speedKmh: 45, forward: true, backward: true},
+				{from: 2, to: 3, speedKmh: 45, forward: true, backward: true},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := newTestGraph(c.nodes, c.roads)
+			_, err := g.Route(context.Background(), c.nodes[0], c.nodes[len(c.nodes)-1])
+			if !errors.Is(err, ErrNoRoute) {
+				t.Errorf("got %v, want ErrNoRoute", err)
+			}
+		})
+	}
+}
+
+func TestGraphRouteSnapsToTheNetwork(t *testing.T) {
+	a, b := at(0, 0), at(0, 500)
+	g := newTestGraph([]purchase_entity.Location{a, b}, []testRoad{
+		{from: 0, to: 1, speedKmh: 36, forward: true, backward: true},
+	})
+
+	// 30 m beside the road at both ends is covered on foot
+	route, err := g.Route(context.Background(), at(30, 0), at(-30, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(route.DistanceKm, 0.56, 0.001) {
+		t.Errorf("route is %.3f km, want 0.5 km of road and 60 m to reach it", route.DistanceKm)
+	}
+	// 50s on the road at 10 m/s and 60 m at the connector speed
+	wantSeconds := 50 + 0.06/connectorSpeedKmh*3600
+	if !near(route.Duration.Seconds(), wantSeconds, 0.5) {
+		t.Errorf("route takes %.1fs, want %.1fs", route.Duration.Seconds(), wantSeconds)
+	}
+
+	for _, p := range []purchase_entity.Location{at(100, 0), at(0, -100)} {
+		if _, err := g.Route(context.Background(), p, b); !errors.Is(err, ErrOutsideNetwork) {
+			t.Errorf("from %v got %v, want ErrOutsideNetwork", p, err)
+		}
+		if _, err := g.Route(context.Background(), a, p); !errors.Is(err, ErrOutsideNetwork) {
+			t.Errorf("to %v got %v, want ErrOutsideNetwork", p, err)
+		}
+	}
+}
+
+func TestGraphRouteStopsWhenCancelled(t *testing.T) {
+	a, b := at(0, 0), at(0, 500)
+	g := newTestGraph([]purchase_entity.Location{a, b}, []testRoad{
+		{from: 0, to: 1, speedKmh: 36, forward: true, backward: true},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.Route(ctx, a, b); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
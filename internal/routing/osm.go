@@ -0,0 +1,178 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/osm"
+	"github.com/paulmach/osm/osmpbf"
+)
+
+// highwaySpeeds are the assumed speeds in km/h of the road classes a delivery
+// rider may use, when a way has no usable maxspeed tag.
+var highwaySpeeds = map[string]float64{
+	"motorway":       80,
+	"motorway_link":  45,
+	"trunk":          60,
+	"trunk_link":     40,
+	"primary":        45,
+	"primary_link":   35,
+	"secondary":      40,
+	"secondary_link": 30,
+	"tertiary":       35,
+	"tertiary_link":  25,
+	"unclassified":   30,
+	"residential":    25,
+	"road":           20,
+	"living_street":  10,
+	"service":        15,
+}
+
+// road is a way that is part of the network, kept between the two passes.
+type road struct {
+	nodes    []osm.NodeID
+	speedKmh float64
+	forward  bool
+	backward bool
+}
+
+// LoadPBF builds the road network from an OSM PBF extract. The file is read
+// twice, first for the drivable ways and then for the coordinates of only the
+// nodes those ways use, which keeps memory close to the size of the network.
+func LoadPBF(ctx context.Context, path string, maxSnapDistanceM float64) (*Graph, error) {
+	roads := []road{}
+	err := scanPBF(ctx, path, func(scanner *osmpbf.Scanner) {
+		scanner.SkipNodes = true
+		scanner.SkipRelations = true
+	}, func(object osm.Object) {
+		if way, ok := object.(*osm.Way); ok {
+			if r, ok := toRoad(way); ok {
+				roads = append(roads, r)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	needed := map[osm.NodeID]int32{}
+	for _, r := range roads {
+		for _, id := range r.nodes {
+			needed[id] = -1
+		}
+	}
+
+	builder := &graphBuilder{}
+	err = scanPBF(ctx, path, func(scanner *osmpbf.Scanner) {
+		scanner.SkipWays = true
+		scanner.SkipRelations = true
+	}, func(object osm.Object) {
+		if node, ok := object.(*osm.Node); ok {
+			if _, ok := needed[node.ID]; ok {
+				needed[node.ID] = builder.addNode(node.Lat, node.Lon)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range roads {
+		for i := 1; i < len(r.nodes); i++ {
+			from, to := needed[r.nodes[i-1]], needed[r.nodes[i]]
+			// extracts are clipped, ways may reference nodes outside of them
+			if from < 0 || to < 0 {
+				continue
+			}
+			builder.addRoad(from, to, r.speedKmh, r.forward, r.backward)
+		}
+	}
+
+	if len(builder.edges) == 0 {
+		return nil, fmt.Errorf("%s has no drivable roads", path)
+	}
+
+	return builder.build(maxSnapDistanceM), nil
+}
+
+func scanPBF(ctx context.Context, path string, configure func(*osmpbf.Scanner), fn func(osm.Object)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := osmpbf.New(ctx, file, runtime.GOMAXPROCS(0))
+	defer scanner.Close()
+	configure(scanner)
+
+	for scanner.Scan() {
+		fn(scanner.Object())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	return nil
+}
+
+// toRoad keeps the ways a motorcycle may ride on along with their speed and
+// allowed directions.
+func toRoad(way *osm.Way) (road, bool) {
+	tags := way.Tags
+	defaultSpeed, ok := highwaySpeeds[tags.Find("highway")]
+	if !ok || tags.Find("area") == "yes" {
+		return road{}, false
+	}
+	for _, key := range []string{"access", "vehicle", "motor_vehicle", "motorcycle"} {
+		switch tags.Find(key) {
+		case "no", "private":
+			return road{}, false
+		}
+	}
+	if len(way.Nodes) < 2 {
+		return road{}, false
+	}
+
+	r := road{
+		nodes:    way.Nodes.NodeIDs(),
+		speedKmh: defaultSpeed,
+		forward:  true,
+		backward: true,
+	}
+	if speed, ok := parseMaxSpeed(tags.Find("maxspeed")); ok {
+		r.speedKmh = speed
+	}
+
+	oneway := tags.Find("oneway")
+	switch {
+	case oneway == "-1" || oneway == "reverse":
+		r.forward = false
+	case oneway == "yes" || oneway == "true" || oneway == "1":
+		r.backward = false
+	case oneway == "no":
+	case tags.Find("highway") == "motorway", tags.Find("junction") == "roundabout", tags.Find("junction") == "circular":
+		r.backward = false
+	}
+
+	return r, true
+}
+
+// parseMaxSpeed reads maxspeed values like "50", "50 km/h" and "30 mph".
+func parseMaxSpeed(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	factor := 1.0
+	if number, ok := strings.CutSuffix(value, "mph"); ok {
+		value, factor = number, 1.609344
+	}
+	value = strings.TrimSpace(strings.TrimSuffix(value, "km/h"))
+
+	speed, err := strconv.ParseFloat(value, 64)
+	if err != nil || speed <= 0 {
+		return 0, false
+	}
+	return speed * factor, true
+}
@@ -0,0 +1,85 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/paulmach/osm"
+)
+
+func TestParseMaxSpeed(t *testing.T) {
+	cases := []struct {
+		value string
+		speed float64
+		ok    bool
+	}{
+		{"50", 50, true},
+		{"50 km/h", 50, true},
+		{"50km/h", 50, true},
+		{" 40 ", 40, true},
+		{"30 mph", 30 * 1.609344, true},
+		{"30mph", 30 * 1.609344, true},
+		{"", 0, false},
+		{"walk", 0, false},
+		{"signals", 0, false},
+		{"0", 0, false},
+		{"-20", 0, false},
+	}
+
+	for _, c := range cases {
+		speed, ok := parseMaxSpeed(c.value)
+		if ok != c.ok || !near(speed, c.speed, 1e-9) {
+			t.Errorf("parseMaxSpeed(%q) = %v, %v, want %v, %v", c.value, speed, ok, c.speed, c.ok)
+		}
+	}
+}
+
+func TestToRoad(t *testing.T) {
+	cases := []struct {
+		name     string
+		tags     osm.Tags
+		nodes    int
+		ok       bool
+		speedKmh float64
+		forward  bool
+		backward bool
+	}{
+		{name: "two-way residential", tags: osm.Tags{{Key: "highway", Value: "residential"}}, nodes: 2, ok: true, speedKmh: 25, forward: true, backward: true},
+		{name: "maxspeed", tags: osm.Tags{{Key: "highway", Value: "primary"}, {Key: "maxspeed", Value: "60"}}, nodes: 2, ok: true, speedKmh: 60, forward: true, backward: true},
+		{name: "unusable maxspeed", tags: osm.Tags{{Key: "highway", Value: "primary"}, {Key: "maxspeed", Value: "none"}}, nodes: 2, ok: true, speedKmh: 45, forward: true, backward: true},
+		{name: "oneway", tags: osm.Tags{{Key: "highway", Value: "secondary"}, {Key: "oneway", Value: "yes"}}, nodes: 2, ok: true, speedKmh: 40, forward: true},
+		{name: "reversed oneway", tags: osm.Tags{{Key: "highway", Value: "secondary"}, {Key: "oneway", Value: "-1"}}, nodes: 2, ok: true, speedKmh: 40, backward: true},
+		{name: "roundabout", tags: osm.Tags{{Key: "highway", Value: "tertiary"}, {Key: "junction", Value: "roundabout"}}, nodes: 3, ok: true, speedKmh: 35, forward: true},
+		{name: "motorway", tags: osm.Tags{{Key: "highway", Value: "motorway"}}, nodes: 2, ok: true, speedKmh: 80, forward: true},
+		{name: "two-way motorway", tags: osm.Tags{{Key: "highway", Value: "motorway"}, {Key: "oneway", Value: "no"}}, nodes: 2, ok: true, speedKmh: 80, forward: true, backward: true},
+		{name: "footway", tags: osm.Tags{{Key: "highway", Value: "footway"}}, nodes: 2},
+		{name: "not a road", tags: osm.Tags{{Key: "building", Value: "yes"}}, nodes: 4},
+		{name: "area", tags: osm.Tags{{Key: "highway", Value: "service"}, {Key: "area", Value: "yes"}}, nodes: 4},
+		{name: "private", tags: osm.Tags{{Key: "highway", Value: "service"}, {Key: "access", Value: "private"}}, nodes: 2},
+		{name: "no motorcycles", tags: osm.Tags{{Key: "highway", Value: "residential"}, {Key: "motorcycle", Value: "no"}}, nodes: 2},
+		{name: "single node", tags: osm.Tags{{Key: "highway", Value: "residential"}}, nodes: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			way := &osm.Way{Tags: c.tags}
+			for i := 0; i < c.nodes; i++ {
+				way.Nodes = append(way.Nodes, osm.WayNode{ID: osm.NodeID(i + 1)})
+			}
+
+			r, ok := toRoad(way)
+			if ok != c.ok {
+				t.Fatalf("toRoad kept the way: %v, want %v", ok, c.ok)
+			}
+			if !ok {
+				return
+			}
+			if r.speedKmh != c.speedKmh || r.forward != c.forward || r.backward != c.backward {
+				t.Errorf("got %v km/h forward %v backward %v, want %v km/h forward %v backward %v",
+					r.speedKmh, r.forward, r.backward, c.speedKmh, c.forward, c.backward)
+			}
+			if len(r.nodes) != c.nodes {
+				t.Errorf("got %d nodes, want %d", len(r.nodes), c.nodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package routing answers how far and how long a trip between two points is.
+// A road network loaded from an OSM extract gives real distances and travel
+// times, and a straight line at a fixed speed is used whenever the network
+// can't answer.
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	formula_helper "github.com/danzBraham/beli-mang/internal/helpers/formula"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/metrics"
+)
+
+var (
+	ErrOutsideNetwork = errors.New("location is too far from the road network")
+	ErrNoRoute        = errors.New("no route between the locations")
+)
+
+type Route struct {
+	DistanceKm float64
+	Duration   time.Duration
+}
+
+type Router interface {
+	Route(ctx context.Context, from, to purchase_entity.Location) (Route, error)
+}
+
+// New returns the router described by cfg: the road network from
+// cfg.PBFPath with a straight line fallback, or only the straight line when
+// no extract is configured.
+func New(cfg config.RoutingConfig) (Router, error) {
+	fallback := NewHaversineRouter(cfg.FallbackSpeedKmh)
+	if cfg.PBFPath == "" {
+		return fallback, nil
+	}
+
+	start := time.Now()
+	graph, err := LoadPBF(context.Background(), cfg.PBFPath, cfg.MaxSnapDistanceM)
+	if err != nil {
+		return nil, fmt.Errorf("load road network: %w", err)
+	}
+	slog.Info("road network loaded",
+		"path", cfg.PBFPath,
+		"nodes", graph.Nodes(),
+		"edges", graph.Edges(),
+		"duration", time.Since(start),
+	)
+
+	return NewFallbackRouter(graph, fallback), nil
+}
+
+// HaversineRouter travels in a straight line at a constant speed.
+type HaversineRouter struct {
+	SpeedKmh float64
+}
+
+func NewHaversineRouter(speedKmh float64) Router {
+	return &HaversineRouter{SpeedKmh: speedKmh}
+}
+
+func (r *HaversineRouter) Route(ctx context.Context, from, to purchase_entity.Location) (Route, error) {
+	distance := formula_helper.Distance(from, to)
+	return Route{
+		DistanceKm: distance,
+		Duration:   time.Duration(distance / r.SpeedKmh * float64(time.Hour)),
+	}, nil
+}
+
+// FallbackRouter asks Primary first and Fallback when Primary fails.
+type FallbackRouter struct {
+	Primary  Router
+	Fallback Router
+}
+
+func NewFallbackRouter(primary, fallback Router) Router {
+	return &FallbackRouter{Primary: primary, Fallback: fallback}
+}
+
+func (r *FallbackRouter) Route(ctx context.Context, from, to purchase_entity.Location) (Route, error) {
+	route, err := r.Primary.Route(ctx, from, to)
+	if err == nil {
+		return route, nil
+	}
+	if ctx.Err() != nil {
+		return Route{}, ctx.Err()
+	}
+
+	reason := "error"
+	switch {
+	case errors.Is(err, ErrOutsideNetwork):
+		reason = "outside_network"
+	case errors.Is(err, ErrNoRoute):
+		reason = "no_route"
+	}
+	metrics.RouteFallbacks.WithLabelValues(reason).Inc()
+	logger.FromContext(ctx).Debug("routing fell back", "reason", reason, "error", err)
+
+	return r.Fallback.Route(ctx, from, to)
+}
@@ -0,0 +1,112 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+)
+
+// routerFunc answers routes with a function, counting the calls.
+type routerFunc struct {
+	calls int
+	fn    func(ctx context.Context) (Route, error)
+}
+
+func (r *routerFunc) Route(ctx context.Context, from, to purchase_entity.Location) (Route, error) {
+	r.calls++
+	return r.fn(ctx)
+}
+
+func TestHaversineRouter(t *testing.T) {
+	router := NewHaversineRouter(30)
+	route, err := router.Route(context.Background(), at(0, 0), at(0, 1500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(route.DistanceKm, 1.5, 0.001) {
+		t.Errorf("route is %.3f km, want 1.5 km", route.DistanceKm)
+	}
+	if !near(route.Duration.Minutes(), 3, 0.01) {
+		t.Errorf("route takes %v, want 3m at 30 km/h", route.Duration)
+	}
+}
+
+func TestFallbackRouter(t *testing.T) {
+	primaryRoute := Route{DistanceKm: 2, Duration: 5 * time.Minute}
+	fallbackRoute := Route{DistanceKm: 1, Duration: 2 * time.Minute}
+	failure := errors.New("boom")
+
+	cases := []struct {
+		name          string
+		primaryErr    error
+		cancel        bool
+		want          Route
+		wantErr       error
+		fallbackCalls int
+	}{
+		{name: "primary answers", want: primaryRoute},
+		{name: "outside the network", primaryErr: ErrOutsideNetwork, want: fallbackRoute, fallbackCalls: 1},
+		{name: "no route", primaryErr: ErrNoRoute, want: fallbackRoute, fallbackCalls: 1},
+		{name: "other error", primaryErr: failure, want: fallbackRoute, fallbackCalls: 1},
+		{name: "cancelled", primaryErr: context.Canceled, cancel: true, wantErr: context.Canceled},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			primary := &routerFunc{fn: func(ctx context.Context) (Route, error) {
+				if c.primaryErr != nil {
+					return Route{}, c.primaryErr
+				}
+				return primaryRoute, nil
+			}}
+			fallback := &routerFunc{fn: func(ctx context.Context) (Route, error) {
+				return fallbackRoute, nil
+			}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if c.cancel {
+				cancel()
+			}
+
+			route, err := NewFallbackRouter(primary, fallback).Route(ctx, at(0, 0), at(0, 1000))
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("got error %v, want %v", err, c.wantErr)
+			}
+			if route != c.want {
+				t.Errorf("got %+v, want %+v", route, c.want)
+			}
+			if primary.calls != 1 || fallback.calls != c.fallbackCalls {
+				t.Errorf("primary called %d times and fallback %d, want 1 and %d", primary.calls, fallback.calls, c.fallbackCalls)
+			}
+		})
+	}
+}
+
+func TestFallbackRouterOverAGraph(t *testing.T) {
+	a, b := at(0, 0), at(0, 500)
+	g := newTestGraph([]purchase_entity.Location{a, b}, []testRoad{
+		{from: 0, to: 1, speedKmh: 36, forward: true, backward: true},
+	})
+	router := NewFallbackRouter(g, NewHaversineRouter(20))
+
+	route, err := router.Route(context.Background(), a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(route.Duration.Seconds(), 50, 0.5) {
+		t.Errorf("on the network the route takes %v, want 50s", route.Duration)
+	}
+
+	// 2 km away from any road, the straight line at 20 km/h answers
+	route, err = router.Route(context.Background(), a, at(2000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(route.Duration.Minutes(), 6, 0.01) {
+		t.Errorf("off the network the route takes %v, want 6m", route.Duration)
+	}
+}
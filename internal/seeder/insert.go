@@ -0,0 +1,171 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Insert writes the dataset in a single transaction using COPY. Rows with a
+// location are copied into temporary tables first because COPY can't encode
+// PostGIS types, then moved over with one INSERT ... SELECT.
+//
+// Every seeded user gets passwordHash. When reset is set all existing data is
+// removed first.
+func Insert(ctx context.Context, db *pgxpool.Pool, data *Dataset, passwordHash string, reset bool) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if reset {
+		query := `TRUNCATE orders, order_items, order_merchants, estimates, items, merchants, user_addresses, users`
+		if _, err := tx.Exec(ctx, query); err != nil {
+			return fmt.Errorf("reset tables: %w", err)
+		}
+	}
+
+	staging := `
+		CREATE TEMPORARY TABLE seed_merchants (
+			id VARCHAR(26), name VARCHAR(30), category VARCHAR(25), image_url TEXT,
+			lat DOUBLE PRECISION, long DOUBLE PRECISION, user_id VARCHAR(26), preparation_minutes INT,
+			created_at TIMESTAMP
+		) ON COMMIT DROP;
+		CREATE TEMPORARY TABLE seed_estimates (
+			id VARCHAR(26), lat DOUBLE PRECISION, long DOUBLE PRECISION,
+			total_price INT, estimated_delivery_time INT,
+			route_distance_km DOUBLE PRECISION, route_duration_seconds INT, preparation_minutes INT,
+			created_at TIMESTAMP
+		) ON COMMIT DROP;
+	`
+	if _, err := tx.Exec(ctx, staging); err != nil {
+		return fmt.Errorf("create staging tables: %w", err)
+	}
+
+	copies := []struct {
+		table   string
+		columns []string
+		rows    [][]any
+	}{
+		{"users", []string{"id", "username", "password", "email", "is_admin", "created_at", "updated_at"}, userRows(data.Users, passwordHash)},
+		{"seed_merchants", []string{"id", "name", "category", "image_url", "lat", "long", "user_id", "preparation_minutes", "created_at"}, merchantRows(data.Merchants)},
+		{"items", []string{"id", "name", "category", "price", "image_url", "merchant_id", "created_at", "updated_at"}, itemRows(data.Items)},
+		{"seed_estimates", []string{"id", "lat", "long", "total_price", "estimated_delivery_time", "route_distance_km", "route_duration_seconds", "preparation_minutes", "created_at"}, estimateRows(data.Estimates)},
+		{"order_merchants", []string{"id", "merchant_id", "total_merchant_price", "is_starting_point", "estimate_id", "created_at", "updated_at"}, orderMerchantRows(data.OrderMerchants)},
+		{"order_items", []string{"id", "item_id", "quantity", "total_item_price", "order_merchant_id", "created_at", "updated_at"}, orderItemRows(data.OrderItems)},
+		{"orders", []string{"id", "estimate_id", "user_id", "delivered_at", "created_at", "updated_at"}, orderRows(data.Orders)},
+	}
+
+	for _, c := range copies {
+		// the staged rows have to land before anything referencing them
+		switch c.table {
+		case "items":
+			if err := moveMerchants(ctx, tx); err != nil {
+				return err
+			}
+		case "order_merchants":
+			if err := moveEstimates(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		count, err := tx.CopyFrom(ctx, pgx.Identifier{c.table}, c.columns, pgx.CopyFromRows(c.rows))
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", c.table, err)
+		}
+		slog.InfoContext(ctx, "rows copied", "table", c.table, "rows", count)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func moveMerchants(ctx context.Context, tx pgx.Tx) error {
+	query := `
+		INSERT INTO merchants (id, name, category, image_url, location, user_id, preparation_minutes, created_at, updated_at)
+		SELECT id, name, category, image_url, ST_SetSRID(ST_MakePoint(long, lat), 4326)::geography, user_id, preparation_minutes, created_at, created_at
+		FROM seed_merchants
+	`
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return fmt.Errorf("move merchants: %w", err)
+	}
+	return nil
+}
+
+func moveEstimates(ctx context.Context, tx pgx.Tx) error {
+	query := `
+		INSERT INTO estimates (
+			id, user_location, total_price, estimated_delivery_time,
+			route_distance_km, route_duration_seconds, preparation_minutes, created_at, updated_at
+		)
+		SELECT id, ST_SetSRID(ST_MakePoint(long, lat), 4326)::geography, total_price, estimated_delivery_time,
+			route_distance_km, route_duration_seconds, preparation_minutes, created_at, created_at
+		FROM seed_estimates
+	`
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return fmt.Errorf("move estimates: %w", err)
+	}
+	return nil
+}
+
+func userRows(users []User, passwordHash string) [][]any {
+	rows := make([][]any, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, []any{u.Id, u.Username, passwordHash, u.Email, u.IsAdmin, u.CreatedAt, u.CreatedAt})
+	}
+	return rows
+}
+
+func merchantRows(merchants []Merchant) [][]any {
+	rows := make([][]any, 0, len(merchants))
+	for _, m := range merchants {
+		rows = append(rows, []any{m.Id, m.Name, m.Category, m.ImageURL, m.Location.Lat, m.Location.Long, m.UserId, m.PreparationMinutes, m.CreatedAt})
+	}
+	return rows
+}
+
+func itemRows(items []Item) [][]any {
+	rows := make([][]any, 0, len(items))
+	for _, i := range items {
+		rows = append(rows, []any{i.Id, i.Name, i.Category, i.Price, i.ImageURL, i.MerchantId, i.CreatedAt, i.CreatedAt})
+	}
+	return rows
+}
+
+func estimateRows(estimates []Estimate) [][]any {
+	rows := make([][]any, 0, len(estimates))
+	for _, e := range estimates {
+		rows = append(rows, []any{
+			e.Id, e.UserLocation.Lat, e.UserLocation.Long, e.TotalPrice, e.EstimatedDeliveryTime,
+			e.RouteDistanceKm, e.RouteDurationSeconds, e.PreparationMinutes, e.CreatedAt,
+		})
+	}
+	return rows
+}
+
+func orderMerchantRows(orderMerchants []OrderMerchant) [][]any {
+	rows := make([][]any, 0, len(orderMerchants))
+	for _, om := range orderMerchants {
+		rows = append(rows, []any{om.Id, om.MerchantId, om.TotalMerchantPrice, om.IsStartingPoint, om.EstimateId, om.CreatedAt, om.CreatedAt})
+	}
+	return rows
+}
+
+func orderItemRows(orderItems []OrderItem) [][]any {
+	rows := make([][]any, 0, len(orderItems))
+	for _, oi := range orderItems {
+		rows = append(rows, []any{oi.Id, oi.ItemId, oi.Quantity, oi.TotalItemPrice, oi.OrderMerchantId, oi.CreatedAt, oi.CreatedAt})
+	}
+	return rows
+}
+
+func orderRows(orders []Order) [][]any {
+	rows := make([][]any, 0, len(orders))
+	for _, o := range orders {
+		rows = append(rows, []any{o.Id, o.EstimateId, o.UserId, o.DeliveredAt, o.CreatedAt, o.CreatedAt})
+	}
+	return rows
+}
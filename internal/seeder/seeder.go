@@ -0,0 +1,439 @@
+// Package seeder generates reproducible fake data for development and load
+// testing. The same Options always produce the same dataset.
+package seeder
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
+	formula_helper "github.com/danzBraham/beli-mang/internal/helpers/formula"
+	"github.com/oklog/ulid/v2"
+)
+
+type Options struct {
+	Seed             uint64
+	Admins           int
+	Users            int
+	Merchants        int
+	ItemsPerCategory int
+	Orders           int
+	Center           purchase_entity.Location
+	RadiusKm         float64
+	// Clusters is the number of districts merchants gather around. The
+	// remaining share of merchants is spread uniformly over the area.
+	Clusters int
+	// Until is the newest creation time, data is spread over the 90 days
+	// before it. It is fixed by default so runs stay reproducible.
+	Until time.Time
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Seed:             1,
+		Admins:           10,
+		Users:            1000,
+		Merchants:        5000,
+		ItemsPerCategory: 3,
+		Orders:           10000,
+		Center:           purchase_entity.Location{Lat: -6.2088, Long: 106.8456},
+		RadiusKm:         15,
+		Clusters:         12,
+		Until:            time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (o Options) Validate() error {
+	var errs []error
+	if o.Admins <= 0 {
+		errs = append(errs, errors.New("admins must be greater than 0"))
+	}
+	if o.Users <= 0 && o.Orders > 0 {
+		errs = append(errs, errors.New("users must be greater than 0 to seed orders"))
+	}
+	if o.Merchants < 0 || o.ItemsPerCategory < 0 || o.Orders < 0 || o.Users < 0 {
+		errs = append(errs, errors.New("counts must not be negative"))
+	}
+	if o.Orders > 0 && (o.Merchants == 0 || o.ItemsPerCategory == 0) {
+		errs = append(errs, errors.New("merchants and items are required to seed orders"))
+	}
+	if o.Center.Lat < -90 || o.Center.Lat > 90 || o.Center.Long < -180 || o.Center.Long > 180 {
+		errs = append(errs, errors.New("center must be a valid coordinate"))
+	}
+	if o.RadiusKm <= 0 {
+		errs = append(errs, errors.New("radius must be greater than 0"))
+	}
+	if o.Clusters <= 0 {
+		errs = append(errs, errors.New("clusters must be greater than 0"))
+	}
+	return errors.Join(errs...)
+}
+
+type User struct {
+	Id        string
+	Username  string
+	Email     string
+	IsAdmin   bool
+	CreatedAt time.Time
+}
+
+type Merchant struct {
+	Id                 string
+	Name               string
+	Category           string
+	ImageURL           string
+	Location           purchase_entity.Location
+	UserId             string
+	PreparationMinutes int
+	CreatedAt          time.Time
+	cluster            int
+}
+
+type Item struct {
+	Id         string
+	Name       string
+	Category   string
+	Price      int
+	ImageURL   string
+	MerchantId string
+	CreatedAt  time.Time
+}
+
+type Estimate struct {
+	Id                    string
+	UserLocation          purchase_entity.Location
+	TotalPrice            int
+	EstimatedDeliveryTime int
+	RouteDistanceKm       float64
+	RouteDurationSeconds  int
+	PreparationMinutes    int
+	CreatedAt             time.Time
+}
+
+type OrderMerchant struct {
+	Id                 string
+	MerchantId         string
+	TotalMerchantPrice int
+	IsStartingPoint    bool
+	EstimateId         string
+	CreatedAt          time.Time
+}
+
+type OrderItem struct {
+	Id              string
+	ItemId          string
+	Quantity        int
+	TotalItemPrice  int
+	OrderMerchantId string
+	CreatedAt       time.Time
+}
+
+type Order struct {
+	Id          string
+	EstimateId  string
+	UserId      string
+	CreatedAt   time.Time
+	DeliveredAt time.Time
+}
+
+type Dataset struct {
+	Users          []User
+	Merchants      []Merchant
+	Items          []Item
+	Estimates      []Estimate
+	OrderMerchants []OrderMerchant
+	OrderItems     []OrderItem
+	Orders         []Order
+}
+
+// clusterShare is the share of merchants placed around district centers.
+const clusterShare = 0.8
+
+const history = 90 * 24 * time.Hour
+
+// Delivery history is simulated with routes this much longer than the
+// straight line, ridden at routeSpeedKmh in free flow and slowed down by
+// traffic at rush hour.
+const (
+	routeDetour    = 1.3
+	routeSpeedKmh  = 30
+	pickupHandling = 3 * time.Minute
+	rushHourFactor = 0.6
+	offPeakFactor  = 0.95
+	weekendFactor  = 1.1
+	deliveryJitter = 0.3
+)
+
+// zone is where rush hours are reckoned, the default center is Jakarta.
+var zone = time.FixedZone("WIB", 7*60*60)
+
+type generator struct {
+	opts    Options
+	rng     *rand.Rand
+	entropy *entropy
+	data    *Dataset
+	// clusters holds the district centers, merchantsByCluster the indexes of
+	// the merchants around each of them, the last entry the scattered ones.
+	clusters           []purchase_entity.Location
+	merchantsByCluster [][]int
+	itemsByMerchant    [][]int
+}
+
+// Generate builds the dataset described by opts.
+func Generate(opts Options) (*Dataset, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+	g := &generator{
+		opts:    opts,
+		rng:     rng,
+		entropy: &entropy{rng: rng},
+		data:    &Dataset{},
+	}
+
+	g.users()
+	g.merchants()
+	g.items()
+	g.orders()
+
+	return g.data, nil
+}
+
+func (g *generator) users() {
+	for i := 0; i < g.opts.Admins+g.opts.Users; i++ {
+		isAdmin := i < g.opts.Admins
+		username := fmt.Sprintf("user%06d", i-g.opts.Admins+1)
+		if isAdmin {
+			username = fmt.Sprintf("admin%04d", i+1)
+		}
+
+		createdAt := g.timeBetween(g.opts.Until.Add(-history), g.opts.Until)
+		g.data.Users = append(g.data.Users, User{
+			Id:        g.id(createdAt),
+			Username:  username,
+			Email:     username + "@example.com",
+			IsAdmin:   isAdmin,
+			CreatedAt: createdAt,
+		})
+	}
+}
+
+func (g *generator) merchants() {
+	// district centers sit in the inner part of the area so their merchants
+	// rarely have to be pulled back inside the radius
+	for i := 0; i < g.opts.Clusters; i++ {
+		g.clusters = append(g.clusters, g.pointInDisc(g.opts.Center, g.opts.RadiusKm*0.8))
+	}
+	g.merchantsByCluster = make([][]int, g.opts.Clusters+1)
+
+	spread := g.opts.RadiusKm / 10
+	for i := 0; i < g.opts.Merchants; i++ {
+		cluster := g.opts.Clusters
+		location := g.pointInDisc(g.opts.Center, g.opts.RadiusKm)
+		if g.rng.Float64() < clusterShare {
+			cluster = g.rng.IntN(g.opts.Clusters)
+			location = g.pointNear(g.clusters[cluster], spread)
+		}
+
+		category := merchantCategories[g.rng.IntN(len(merchantCategories))]
+		prefixes := merchantPrefixes[category]
+		name := fmt.Sprintf("%s %s %d",
+			prefixes[g.rng.IntN(len(prefixes))],
+			merchantNames[g.rng.IntN(len(merchantNames))],
+			i+1,
+		)
+
+		owner := g.data.Users[g.rng.IntN(g.opts.Admins)]
+		createdAt := g.timeBetween(owner.CreatedAt, g.opts.Until)
+		id := g.id(createdAt)
+		preparationMinutes := 5 + g.rng.IntN(16)
+		g.data.Merchants = append(g.data.Merchants, Merchant{
+			Id:                 id,
+			Name:               name,
+			Category:           category,
+			ImageURL:           "https://picsum.photos/seed/" + id + "/640/480.jpg",
+			Location:           location,
+			UserId:             owner.Id,
+			PreparationMinutes: preparationMinutes,
+			CreatedAt:          createdAt,
+			cluster:            cluster,
+		})
+		g.merchantsByCluster[cluster] = append(g.merchantsByCluster[cluster], i)
+	}
+}
+
+func (g *generator) items() {
+	g.itemsByMerchant = make([][]int, len(g.data.Merchants))
+	for m, merchant := range g.data.Merchants {
+		for _, category := range itemCategories {
+			templates := itemTemplates[category]
+			for _, t := range g.rng.Perm(len(templates))[:min(g.opts.ItemsPerCategory, len(templates))] {
+				template := templates[t]
+				// prices are rounded to 500 like real menus
+				price := template.minPrice + g.rng.IntN(template.maxPrice-template.minPrice+1)
+				price = max(500, price/500*500)
+
+				createdAt := g.timeBetween(merchant.CreatedAt, g.opts.Until)
+				id := g.id(createdAt)
+				g.itemsByMerchant[m] = append(g.itemsByMerchant[m], len(g.data.Items))
+				g.data.Items = append(g.data.Items, Item{
+					Id:         id,
+					Name:       template.name,
+					Category:   category,
+					Price:      price,
+					ImageURL:   "https://picsum.photos/seed/" + id + "/320/320.jpg",
+					MerchantId: merchant.Id,
+					CreatedAt:  createdAt,
+				})
+			}
+		}
+	}
+}
+
+// orders creates ordered estimates, each from one to three merchants of the
+// same district delivered to a customer nearby.
+func (g *generator) orders() {
+	customers := g.data.Users[g.opts.Admins:]
+	for i := 0; i < g.opts.Orders; i++ {
+		customer := customers[g.rng.IntN(len(customers))]
+
+		cluster := g.rng.IntN(len(g.merchantsByCluster))
+		for len(g.merchantsByCluster[cluster]) == 0 {
+			cluster = (cluster + 1) % len(g.merchantsByCluster)
+		}
+		candidates := g.merchantsByCluster[cluster]
+
+		first := g.data.Merchants[candidates[g.rng.IntN(len(candidates))]]
+		userLocation := g.pointNear(first.Location, 2)
+
+		createdAt := g.timeBetween(customer.CreatedAt, g.opts.Until)
+		estimate := Estimate{
+			Id:           g.id(createdAt),
+			UserLocation: userLocation,
+			CreatedAt:    createdAt,
+		}
+
+		picked := map[int]bool{}
+		merchantCount := 1 + g.rng.IntN(min(3, len(candidates)))
+		var maxDistance float64
+		var preparation time.Duration
+		for len(picked) < merchantCount {
+			m := candidates[g.rng.IntN(len(candidates))]
+			if picked[m] {
+				continue
+			}
+			picked[m] = true
+			merchant := g.data.Merchants[m]
+
+			orderMerchant := OrderMerchant{
+				Id:              g.id(createdAt),
+				MerchantId:      merchant.Id,
+				IsStartingPoint: len(picked) == 1,
+				EstimateId:      estimate.Id,
+				CreatedAt:       createdAt,
+			}
+
+			items := g.itemsByMerchant[m]
+			for _, it := range g.rng.Perm(len(items))[:1+g.rng.IntN(min(3, len(items)))] {
+				item := g.data.Items[items[it]]
+				quantity := 1 + g.rng.IntN(5)
+				orderItem := OrderItem{
+					Id:              g.id(createdAt),
+					ItemId:          item.Id,
+					Quantity:        quantity,
+					TotalItemPrice:  quantity * item.Price,
+					OrderMerchantId: orderMerchant.Id,
+					CreatedAt:       createdAt,
+				}
+				orderMerchant.TotalMerchantPrice += orderItem.TotalItemPrice
+				g.data.OrderItems = append(g.data.OrderItems, orderItem)
+			}
+
+			estimate.TotalPrice += orderMerchant.TotalMerchantPrice
+			maxDistance = math.Max(maxDistance, formula_helper.Distance(userLocation, merchant.Location))
+			preparation = max(preparation, time.Duration(merchant.PreparationMinutes)*time.Minute)
+			g.data.OrderMerchants = append(g.data.OrderMerchants, orderMerchant)
+		}
+		preparation += time.Duration(merchantCount-1) * pickupHandling
+
+		routeDistance := maxDistance * routeDetour
+		routeDuration := time.Duration(routeDistance / routeSpeedKmh * float64(time.Hour)).Round(time.Second)
+		estimate.RouteDistanceKm = routeDistance
+		estimate.RouteDurationSeconds = int(routeDuration / time.Second)
+		estimate.PreparationMinutes = int(preparation / time.Minute)
+		estimate.EstimatedDeliveryTime = int((preparation + routeDuration).Round(time.Minute) / time.Minute)
+		g.data.Estimates = append(g.data.Estimates, estimate)
+
+		orderedAt := createdAt.Add(time.Duration(1+g.rng.IntN(10)) * time.Minute)
+		jitter := 1 + deliveryJitter*(2*g.rng.Float64()-1)
+		travel := time.Duration(float64(routeDuration) / trafficFactor(orderedAt) * jitter)
+		g.data.Orders = append(g.data.Orders, Order{
+			Id:          g.id(orderedAt),
+			EstimateId:  estimate.Id,
+			UserId:      customer.Id,
+			CreatedAt:   orderedAt,
+			DeliveredAt: orderedAt.Add(preparation + travel).Truncate(time.Second),
+		})
+	}
+}
+
+// trafficFactor is how fast traffic moves at t relative to free flow.
+func trafficFactor(t time.Time) float64 {
+	t = t.In(zone)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return weekendFactor
+	}
+	switch hour := t.Hour(); {
+	case hour >= 7 && hour < 9, hour >= 17 && hour < 19:
+		return rushHourFactor
+	default:
+		return offPeakFactor
+	}
+}
+
+// pointInDisc returns a point uniformly distributed over the disc.
+func (g *generator) pointInDisc(center purchase_entity.Location, radius float64) purchase_entity.Location {
+	distance := radius * math.Sqrt(g.rng.Float64())
+	return formula_helper.Destination(center, g.rng.Float64()*360, distance)
+}
+
+// pointNear returns a point normally distributed around center, kept inside
+// the seeding area.
+func (g *generator) pointNear(center purchase_entity.Location, spread float64) purchase_entity.Location {
+	for {
+		distance := math.Abs(g.rng.NormFloat64()) * spread
+		point := formula_helper.Destination(center, g.rng.Float64()*360, distance)
+		if formula_helper.Distance(g.opts.Center, point) <= g.opts.RadiusKm {
+			return point
+		}
+	}
+}
+
+func (g *generator) timeBetween(from, to time.Time) time.Time {
+	if !to.After(from) {
+		return from
+	}
+	offset := time.Duration(g.rng.Int64N(int64(to.Sub(from))))
+	return from.Add(offset).Truncate(time.Second)
+}
+
+func (g *generator) id(t time.Time) string {
+	return ulid.MustNew(ulid.Timestamp(t), g.entropy).String()
+}
+
+// entropy feeds ULIDs from the seeded generator instead of crypto/rand.
+type entropy struct {
+	rng *rand.Rand
+}
+
+func (e *entropy) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(e.rng.Uint32())
+	}
+	return len(p), nil
+}
@@ -0,0 +1,72 @@
+package seeder
+
+import (
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+)
+
+var merchantCategories = []string{
+	merchant_entity.SmallRestaurant,
+	merchant_entity.MediumRestaurant,
+	merchant_entity.LargeRestaurant,
+	merchant_entity.MerchandiseRestaurant,
+	merchant_entity.BoothKiosk,
+	merchant_entity.ConvenienceStore,
+}
+
+// merchantPrefixes are picked by category so names read like the place.
+var merchantPrefixes = map[string][]string{
+	merchant_entity.SmallRestaurant:       {"Warung", "Kedai", "Depot", "Lapo"},
+	merchant_entity.MediumRestaurant:      {"Rumah Makan", "Resto", "Dapur", "Bistro"},
+	merchant_entity.LargeRestaurant:       {"Restoran", "Grand Resto", "Saung", "Pendopo"},
+	merchant_entity.MerchandiseRestaurant: {"Toko Oleh", "Galeri Rasa", "Pusat Jajan", "Toko Kue"},
+	merchant_entity.BoothKiosk:            {"Booth", "Kios", "Gerobak", "Lapak"},
+	merchant_entity.ConvenienceStore:      {"Mart", "Minimarket", "Toserba", "Swalayan"},
+}
+
+var merchantNames = []string{
+	"Sari", "Bu Tini", "Pak Darto", "Sederhana", "Nusantara", "Mang Ujang",
+	"Bahagia", "Selera", "Barokah", "Sentosa", "Jaya", "Melati", "Kenanga",
+	"Cempaka", "Mawar", "Lestari", "Sejahtera", "Makmur", "Rasa Kita", "Pojok",
+	"Kita", "Sinar", "Bintang", "Purnama", "Mekar", "Citra", "Pelangi", "Abadi",
+}
+
+type itemTemplate struct {
+	name     string
+	minPrice int
+	maxPrice int
+}
+
+var itemCategories = []string{
+	item_entity.Beverage,
+	item_entity.Food,
+	item_entity.Snack,
+	item_entity.Condiments,
+	item_entity.Additions,
+}
+
+var itemTemplates = map[string][]itemTemplate{
+	item_entity.Beverage: {
+		{"Es Teh Manis", 3000, 8000}, {"Kopi Susu", 12000, 28000}, {"Jus Alpukat", 10000, 25000},
+		{"Es Jeruk", 5000, 12000}, {"Teh Tarik", 8000, 18000}, {"Es Cendol", 8000, 15000},
+		{"Air Mineral", 3000, 6000}, {"Wedang Jahe", 6000, 12000},
+	},
+	item_entity.Food: {
+		{"Nasi Goreng", 15000, 35000}, {"Mie Ayam", 12000, 25000}, {"Sate Ayam", 20000, 40000},
+		{"Nasi Padang", 18000, 45000}, {"Soto Betawi", 20000, 38000}, {"Gado Gado", 12000, 25000},
+		{"Bakso Urat", 15000, 30000}, {"Ayam Geprek", 15000, 28000}, {"Rendang", 25000, 50000},
+	},
+	item_entity.Snack: {
+		{"Pisang Goreng", 5000, 15000}, {"Martabak Manis", 20000, 60000}, {"Cireng", 5000, 12000},
+		{"Tahu Isi", 5000, 12000}, {"Risoles", 4000, 10000}, {"Kue Cubit", 8000, 20000},
+		{"Keripik Singkong", 8000, 20000},
+	},
+	item_entity.Condiments: {
+		{"Sambal Matah", 3000, 8000}, {"Sambal Terasi", 3000, 8000}, {"Kecap Manis", 2000, 5000},
+		{"Saus Kacang", 3000, 7000}, {"Acar", 2000, 5000},
+	},
+	item_entity.Additions: {
+		{"Telur Ceplok", 4000, 8000}, {"Kerupuk", 2000, 5000}, {"Nasi Putih", 4000, 8000},
+		{"Extra Keju", 5000, 10000}, {"Tempe Goreng", 3000, 6000}, {"Perkedel", 3000, 7000},
+	},
+}
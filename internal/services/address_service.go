@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+
+	address_entity "github.com/danzBraham/beli-mang/internal/entities/address"
+	address_exception "github.com/danzBraham/beli-mang/internal/exceptions/address"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
+	"github.com/oklog/ulid/v2"
+)
+
+type AddressService interface {
+	CreateAddress(ctx context.Context, userId string, payload *address_entity.AddressRequest) (*address_entity.AddAddressResponse, error)
+	GetAddresses(ctx context.Context, userId string) (*address_entity.GetAddressesResponse, error)
+	GetAddress(ctx context.Context, userId, addressId string) (*address_entity.GetAddress, error)
+	UpdateAddress(ctx context.Context, userId, addressId string, payload *address_entity.AddressRequest) (*address_entity.GetAddress, error)
+	DeleteAddress(ctx context.Context, userId, addressId string) error
+}
+
+type AddressServiceImpl struct {
+	Repository repositories.AddressRepository
+	Geocoder   geocoding.Geocoder
+}
+
+func NewAddressService(repository repositories.AddressRepository, geocoder geocoding.Geocoder) AddressService {
+	return &AddressServiceImpl{Repository: repository, Geocoder: geocoder}
+}
+
+func (s *AddressServiceImpl) CreateAddress(ctx context.Context, userId string, payload *address_entity.AddressRequest) (*address_entity.AddAddressResponse, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.CreateAddress")
+	defer span.End()
+
+	count, err := s.Repository.CountAddresses(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if count >= address_entity.MaxAddresses {
+		return nil, address_exception.ErrTooManyAddresses
+	}
+
+	isLabelExists, err := s.Repository.VerifyLabel(ctx, userId, payload.Label, "")
+	if err != nil {
+		return nil, err
+	}
+	if isLabelExists {
+		return nil, address_exception.ErrLabelAlreadyExists
+	}
+
+	address := &address_entity.Address{Id: ulid.Make().String(), UserId: userId}
+	if err := s.fill(ctx, address, payload); err != nil {
+		return nil, err
+	}
+
+	err = s.Repository.CreateAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("address saved", "address_id", address.Id, "is_default", address.IsDefault)
+
+	return &address_entity.AddAddressResponse{Id: address.Id}, nil
+}
+
+func (s *AddressServiceImpl) GetAddresses(ctx context.Context, userId string) (*address_entity.GetAddressesResponse, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.GetAddresses")
+	defer span.End()
+
+	addresses, err := s.Repository.GetAddresses(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	getAddresses := make([]*address_entity.GetAddress, 0, len(addresses))
+	for _, address := range addresses {
+		getAddresses = append(getAddresses, toGetAddress(address))
+	}
+
+	return &address_entity.GetAddressesResponse{Data: getAddresses}, nil
+}
+
+func (s *AddressServiceImpl) GetAddress(ctx context.Context, userId, addressId string) (*address_entity.GetAddress, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.GetAddress")
+	defer span.End()
+
+	address, err := s.Repository.GetAddressById(ctx, userId, addressId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGetAddress(address), nil
+}
+
+func (s *AddressServiceImpl) UpdateAddress(ctx context.Context, userId, addressId string, payload *address_entity.AddressRequest) (*address_entity.GetAddress, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.UpdateAddress")
+	defer span.End()
+
+	isLabelExists, err := s.Repository.VerifyLabel(ctx, userId, payload.Label, addressId)
+	if err != nil {
+		return nil, err
+	}
+	if isLabelExists {
+		return nil, address_exception.ErrLabelAlreadyExists
+	}
+
+	address := &address_entity.Address{Id: addressId, UserId: userId}
+	if err := s.fill(ctx, address, payload); err != nil {
+		return nil, err
+	}
+
+	err = s.Repository.UpdateAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("address updated", "address_id", address.Id)
+
+	return toGetAddress(address), nil
+}
+
+func (s *AddressServiceImpl) DeleteAddress(ctx context.Context, userId, addressId string) error {
+	ctx, span := tracing.Start(ctx, "AddressService.DeleteAddress")
+	defer span.End()
+
+	err := s.Repository.DeleteAddress(ctx, userId, addressId)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("address deleted", "address_id", addressId)
+
+	return nil
+}
+
+// fill copies the request onto the address, geocoding the street when no
+// location was sent. The street is kept as the user typed it since that is
+// what the courier reads.
+func (s *AddressServiceImpl) fill(ctx context.Context, address *address_entity.Address, payload *address_entity.AddressRequest) error {
+	address.Label = payload.Label
+	address.IsDefault = payload.IsDefault
+	if payload.Street != "" {
+		address.Street = &payload.Street
+	}
+	if payload.Notes != "" {
+		address.Notes = &payload.Notes
+	}
+
+	if payload.Location != nil {
+		address.Location = *payload.Location
+		return nil
+	}
+
+	place, err := geocodeAddress(ctx, s.Geocoder, payload.Street)
+	if err != nil {
+		return err
+	}
+	address.Location = place.Location
+	return nil
+}
+
+func toGetAddress(address *address_entity.Address) *address_entity.GetAddress {
+	return &address_entity.GetAddress{
+		Id:        address.Id,
+		Label:     address.Label,
+		Location:  address.Location,
+		Street:    address.Street,
+		Notes:     address.Notes,
+		IsDefault: address.IsDefault,
+		CreatedAt: address.CreatedAt,
+		UpdatedAt: address.UpdatedAt,
+	}
+}
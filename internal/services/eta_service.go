@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/danzBraham/beli-mang/internal/config"
+	eta_entity "github.com/danzBraham/beli-mang/internal/entities/eta"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
+)
+
+// factor bounds keep a few bogus delivery timestamps from skewing a profile
+const (
+	minFactor = 0.1
+	maxFactor = 10
+)
+
+type EtaService interface {
+	Estimate(ctx context.Context, at time.Time, trip *eta_entity.Trip) *eta_entity.Estimate
+	LoadSpeedProfiles(ctx context.Context) error
+	GetSpeedProfiles(ctx context.Context) ([]*eta_entity.SpeedProfile, error)
+	Calibrate(ctx context.Context) (*eta_entity.CalibrationReport, error)
+	RunCalibration(ctx context.Context)
+}
+
+type EtaServiceImpl struct {
+	Repository repositories.EtaRepository
+	Config     config.ETAConfig
+	location   *time.Location
+
+	mu       sync.RWMutex
+	profiles [eta_entity.HoursPerWeek]*eta_entity.SpeedProfile
+}
+
+func NewEtaService(repository repositories.EtaRepository, cfg config.ETAConfig) EtaService {
+	// the zone is checked when the configuration is validated
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	return &EtaServiceImpl{
+		Repository: repository,
+		Config:     cfg,
+		location:   location,
+	}
+}
+
+// hourOfWeek returns the bucket of t in the configured zone.
+func (s *EtaServiceImpl) hourOfWeek(t time.Time) int {
+	t = t.In(s.location)
+	// time.Weekday starts on Sunday, the profiles on Monday
+	day := (int(t.Weekday()) + 6) % 7
+	return day*24 + t.Hour()
+}
+
+// profile returns the calibrated profile for the hour or, until there is
+// one, free flow spread by RangeFraction.
+func (s *EtaServiceImpl) profile(hour int) *eta_entity.SpeedProfile {
+	s.mu.RLock()
+	profile := s.profiles[hour]
+	s.mu.RUnlock()
+	if profile != nil {
+		return profile
+	}
+	return &eta_entity.SpeedProfile{
+		HourOfWeek: hour,
+		Factor:     1,
+		FactorLow:  1 / (1 + s.Config.RangeFraction),
+		FactorHigh: 1 / (1 - s.Config.RangeFraction),
+	}
+}
+
+// Estimate adds the slowest merchant's preparation time and a handling time
+// for every pickup after the first to the travel time scaled by the speed
+// profile of the hour the order is placed.
+func (s *EtaServiceImpl) Estimate(ctx context.Context, at time.Time, trip *eta_entity.Trip) *eta_entity.Estimate {
+	var preparation time.Duration
+	for _, minutes := range trip.PreparationMinutes {
+		merchantPreparation := s.Config.DefaultPreparationTime
+		if minutes != nil {
+			merchantPreparation = time.Duration(*minutes) * time.Minute
+		}
+		preparation = max(preparation, merchantPreparation)
+	}
+	if pickups := len(trip.PreparationMinutes); pickups > 1 {
+		preparation += time.Duration(pickups-1) * s.Config.PickupHandlingTime
+	}
+
+	profile := s.profile(s.hourOfWeek(at))
+	minutes := func(factor float64) int {
+		travel := time.Duration(float64(trip.Travel) / factor)
+		return int((preparation + travel).Round(time.Minute) / time.Minute)
+	}
+
+	return &eta_entity.Estimate{
+		Minutes:            minutes(profile.Factor),
+		MinMinutes:         minutes(profile.FactorHigh),
+		MaxMinutes:         minutes(profile.FactorLow),
+		PreparationMinutes: int(preparation.Round(time.Minute) / time.Minute),
+	}
+}
+
+func (s *EtaServiceImpl) LoadSpeedProfiles(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "EtaService.LoadSpeedProfiles")
+	defer span.End()
+
+	profiles, err := s.Repository.GetSpeedProfiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	var byHour [eta_entity.HoursPerWeek]*eta_entity.SpeedProfile
+	for _, profile := range profiles {
+		byHour[profile.HourOfWeek] = profile
+	}
+
+	s.mu.Lock()
+	s.profiles = byHour
+	s.mu.Unlock()
+
+	logger.FromContext(ctx).Info("speed profiles loaded", "hours", len(profiles))
+	return nil
+}
+
+func (s *EtaServiceImpl) GetSpeedProfiles(ctx context.Context) ([]*eta_entity.SpeedProfile, error) {
+	ctx, span := tracing.Start(ctx, "EtaService.GetSpeedProfiles")
+	defer span.End()
+
+	return s.Repository.GetSpeedProfiles(ctx)
+}
+
+// Calibrate fits a speed profile for every hour of the week with enough
+// deliveries in the calibration window. A delivery's factor is the routed
+// travel time over the observed one, the time between ordering and delivery
+// minus the preparation the estimate allowed for. Each hour keeps the median
+// factor, bounded by the 10th and 90th percentiles. Hours without enough
+// deliveries keep their stored profile, or free flow if they have none.
+func (s *EtaServiceImpl) Calibrate(ctx context.Context) (*eta_entity.CalibrationReport, error) {
+	ctx, span := tracing.Start(ctx, "EtaService.Calibrate")
+	defer span.End()
+
+	deliveries, err := s.Repository.GetDeliveries(ctx, time.Now().Add(-s.Config.CalibrationWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &eta_entity.CalibrationReport{Deliveries: len(deliveries)}
+	factors := make([][]float64, eta_entity.HoursPerWeek)
+	for _, delivery := range deliveries {
+		preparation := time.Duration(delivery.PreparationMinutes) * time.Minute
+		travel := delivery.DeliveredAt.Sub(delivery.OrderedAt) - preparation
+		if travel <= 0 {
+			continue
+		}
+		factor := float64(time.Duration(delivery.RouteDurationSeconds)*time.Second) / float64(travel)
+		if factor < minFactor || factor > maxFactor {
+			continue
+		}
+		hour := s.hourOfWeek(delivery.OrderedAt)
+		factors[hour] = append(factors[hour], factor)
+		report.Used++
+	}
+
+	profiles := []*eta_entity.SpeedProfile{}
+	for hour, hourFactors := range factors {
+		if len(hourFactors) < s.Config.CalibrationMinSamples {
+			continue
+		}
+		slices.Sort(hourFactors)
+		profiles = append(profiles, &eta_entity.SpeedProfile{
+			HourOfWeek: hour,
+			Factor:     percentile(hourFactors, 0.5),
+			FactorLow:  percentile(hourFactors, 0.1),
+			FactorHigh: percentile(hourFactors, 0.9),
+			Samples:    len(hourFactors),
+		})
+	}
+	report.Hours = len(profiles)
+
+	if len(profiles) == 0 {
+		logger.FromContext(ctx).Info("speed profiles not calibrated, no hour has enough deliveries",
+			"deliveries", report.Deliveries,
+			"used", report.Used,
+		)
+		return report, nil
+	}
+
+	if err := s.Repository.UpsertSpeedProfiles(ctx, profiles); err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("speed profiles calibrated",
+		"deliveries", report.Deliveries,
+		"used", report.Used,
+		"hours", report.Hours,
+	)
+
+	if err := s.LoadSpeedProfiles(ctx); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating
+// between the closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// RunCalibration loads the stored speed profiles and then, every
+// CalibrationInterval until ctx is cancelled, either recalibrates them or
+// reloads what another instance calibrated. Only the instance holding the
+// calibration lock calibrates. With no interval only the stored profiles are
+// loaded.
+func (s *EtaServiceImpl) RunCalibration(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	if err := s.LoadSpeedProfiles(ctx); err != nil {
+		log.Error("failed to load speed profiles", "error", err)
+	}
+	if s.Config.CalibrationInterval <= 0 {
+		return
+	}
+
+	var lock *repositories.AdvisoryLock
+	defer func() {
+		if lock != nil {
+			lock.Release()
+		}
+	}()
+
+	ticker := time.NewTicker(s.Config.CalibrationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if lock != nil && !lock.Held(ctx) {
+			lock.Release()
+			lock = nil
+		}
+		if lock == nil {
+			var err error
+			if lock, err = s.Repository.TryCalibrationLock(ctx); err != nil && ctx.Err() == nil {
+				log.Error("failed to take the calibration lock", "error", err)
+			}
+		}
+
+		if lock == nil {
+			if err := s.LoadSpeedProfiles(ctx); err != nil && ctx.Err() == nil {
+				log.Error("failed to load speed profiles", "error", err)
+			}
+			continue
+		}
+		if _, err := s.Calibrate(ctx); err != nil && ctx.Err() == nil {
+			log.Error("failed to calibrate speed profiles", "error", err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/danzBraham/beli-mang/db/migrations"
+	"github.com/danzBraham/beli-mang/internal/config"
+	health_entity "github.com/danzBraham/beli-mang/internal/entities/health"
+	health_exception "github.com/danzBraham/beli-mang/internal/exceptions/health"
+	s3_helper "github.com/danzBraham/beli-mang/internal/helpers/s3"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+)
+
+const dependencyCheckTimeout = 2 * time.Second
+
+type HealthService interface {
+	Liveness(ctx context.Context) *health_entity.HealthResponse
+	Readiness(ctx context.Context) *health_entity.HealthResponse
+	MarkShuttingDown()
+}
+
+type HealthServiceImpl struct {
+	Repository   repositories.HealthRepository
+	AWSConfig    config.AWSConfig
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(repository repositories.HealthRepository, awsConfig config.AWSConfig) HealthService {
+	return &HealthServiceImpl{
+		Repository: repository,
+		AWSConfig:  awsConfig,
+	}
+}
+
+type dependencyCheck func(ctx context.Context) (detail string, err error)
+
+func (s *HealthServiceImpl) Liveness(ctx context.Context) *health_entity.HealthResponse {
+	return &health_entity.HealthResponse{Status: health_entity.StatusUp}
+}
+
+func (s *HealthServiceImpl) Readiness(ctx context.Context) *health_entity.HealthResponse {
+	checks := map[string]dependencyCheck{
+		"database":    s.checkDatabase,
+		"postgis":     s.checkPostGIS,
+		"migrations":  s.checkMigrations,
+		"objectStore": s.checkObjectStore,
+	}
+
+	response := &health_entity.HealthResponse{
+		Status:       health_entity.StatusUp,
+		Dependencies: make(map[string]*health_entity.DependencyStatus, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check dependencyCheck) {
+			defer wg.Done()
+			status := runDependencyCheck(ctx, name, check)
+			mu.Lock()
+			response.Dependencies[name] = status
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, status := range response.Dependencies {
+		if status.Status != health_entity.StatusUp {
+			response.Status = health_entity.StatusDown
+		}
+	}
+
+	if s.shuttingDown.Load() {
+		response.Status = health_entity.StatusDown
+		response.Dependencies["server"] = &health_entity.DependencyStatus{
+			Status: health_entity.StatusDown,
+		}
+		logger.FromContext(ctx).Info("readiness reported down", "error", health_exception.ErrShuttingDown)
+	}
+
+	return response
+}
+
+func (s *HealthServiceImpl) MarkShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// runDependencyCheck reports only whether a dependency is up; the detail and
+// error stay in the logs so /readyz doesn't leak hosts, versions or bucket names.
+func runDependencyCheck(ctx context.Context, name string, check dependencyCheck) *health_entity.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	status := &health_entity.DependencyStatus{
+		Status:    health_entity.StatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = health_entity.StatusDown
+		logger.FromContext(ctx).Warn("dependency check failed", "dependency", name, "detail", detail, "error", err)
+	}
+	return status
+}
+
+func (s *HealthServiceImpl) checkDatabase(ctx context.Context) (string, error) {
+	return "", s.Repository.Ping(ctx)
+}
+
+func (s *HealthServiceImpl) checkPostGIS(ctx context.Context) (string, error) {
+	version, err := s.Repository.GetPostGISVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "version " + version, nil
+}
+
+func (s *HealthServiceImpl) checkMigrations(ctx context.Context) (string, error) {
+	latest, err := migrations.LatestVersion()
+	if err != nil {
+		return "", err
+	}
+
+	version, dirty, err := s.Repository.GetMigrationVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	detail := fmt.Sprintf("version %d, latest %d", version, latest)
+	if dirty {
+		return detail, health_exception.ErrMigrationsDirty
+	}
+	if version < latest {
+		return detail, health_exception.ErrMigrationsOutdated
+	}
+	return detail, nil
+}
+
+func (s *HealthServiceImpl) checkObjectStore(ctx context.Context) (string, error) {
+	client, err := s3_helper.NewClient(ctx, s.AWSConfig)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.AWSConfig.BucketName),
+	})
+	if err != nil {
+		return "", err
+	}
+	return "bucket " + s.AWSConfig.BucketName, nil
+}
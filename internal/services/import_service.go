@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+
+	bulk_entity "github.com/danzBraham/beli-mang/internal/entities/bulk"
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	"github.com/danzBraham/beli-mang/internal/exceptions"
+	bulk_exception "github.com/danzBraham/beli-mang/internal/exceptions/bulk"
+	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
+	validator_helper "github.com/danzBraham/beli-mang/internal/helpers/validator"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
+	"github.com/oklog/ulid/v2"
+)
+
+type ImportService interface {
+	ImportMerchants(ctx context.Context, userId string, rows []bulk_entity.Row[merchant_entity.AddMerchantRequest], opts *bulk_entity.ImportOptions) (*bulk_entity.ImportReport, error)
+	ImportItems(ctx context.Context, rows []bulk_entity.Row[item_entity.ImportItemRequest], opts *bulk_entity.ImportOptions) (*bulk_entity.ImportReport, error)
+	ExportMerchants(ctx context.Context, fn func(merchant *merchant_entity.Merchant) error) error
+	ExportItems(ctx context.Context, merchantId string, fn func(item *item_entity.Item) error) error
+}
+
+type ImportServiceImpl struct {
+	MerchantRepository repositories.MerchantRepository
+	ItemRepository     repositories.ItemRepository
+	Geocoder           geocoding.Geocoder
+}
+
+func NewImportService(merchantRepository repositories.MerchantRepository, itemRepository repositories.ItemRepository, geocoder geocoding.Geocoder) ImportService {
+	return &ImportServiceImpl{
+		MerchantRepository: merchantRepository,
+		ItemRepository:     itemRepository,
+		Geocoder:           geocoder,
+	}
+}
+
+func (s *ImportServiceImpl) ImportMerchants(ctx context.Context, userId string, rows []bulk_entity.Row[merchant_entity.AddMerchantRequest], opts *bulk_entity.ImportOptions) (*bulk_entity.ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportMerchants")
+	defer span.End()
+
+	report, err := newImportReport(len(rows), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	merchants := []*merchant_entity.Merchant{}
+	for _, row := range rows {
+		if !report.check(row.Line, row.Err, row.Payload, opts.Language) {
+			continue
+		}
+
+		location, address, err := placeMerchant(ctx, s.Geocoder, row.Payload)
+		if err != nil {
+			report.reject(row.Line, err)
+			continue
+		}
+
+		merchant := &merchant_entity.Merchant{
+			Id:                 ulid.Make().String(),
+			Name:               row.Payload.Name,
+			Category:           row.Payload.Category,
+			ImageURL:           row.Payload.ImageURL,
+			Location:           location,
+			UserId:             userId,
+			Address:            address,
+			PreparationMinutes: row.Payload.PreparationMinutes,
+		}
+		merchants = append(merchants, merchant)
+		report.Created = append(report.Created, bulk_entity.RowResult{Line: row.Line, Id: merchant.Id})
+	}
+
+	if !report.shouldImport(opts) {
+		return report.ImportReport, nil
+	}
+
+	err = s.MerchantRepository.CreateMerchants(ctx, merchants)
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = len(merchants)
+	logger.FromContext(ctx).Info("merchants imported", "imported", report.Imported, "failed", report.Failed, "mode", opts.Mode)
+
+	return report.ImportReport, nil
+}
+
+func (s *ImportServiceImpl) ImportItems(ctx context.Context, rows []bulk_entity.Row[item_entity.ImportItemRequest], opts *bulk_entity.ImportOptions) (*bulk_entity.ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportItems")
+	defer span.End()
+
+	report, err := newImportReport(len(rows), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := []bulk_entity.Row[item_entity.ImportItemRequest]{}
+	merchantIds := []string{}
+	for _, row := range rows {
+		if report.check(row.Line, row.Err, row.Payload, opts.Language) {
+			valid = append(valid, row)
+			merchantIds = append(merchantIds, row.Payload.MerchantId)
+		}
+	}
+
+	existing, err := s.MerchantRepository.GetExistingIds(ctx, merchantIds)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*item_entity.Item{}
+	for _, row := range valid {
+		if !existing[row.Payload.MerchantId] {
+			report.fail(row.Line, merchant_exception.ErrMerchantIdNotFound)
+			report.Valid--
+			continue
+		}
+
+		item := &item_entity.Item{
+			Id:         ulid.Make().String(),
+			Name:       row.Payload.Name,
+			Category:   row.Payload.Category,
+			Price:      row.Payload.Price,
+			ImageURL:   row.Payload.ImageURL,
+			MerchantId: row.Payload.MerchantId,
+		}
+		items = append(items, item)
+		report.Created = append(report.Created, bulk_entity.RowResult{Line: row.Line, Id: item.Id})
+	}
+
+	if !report.shouldImport(opts) {
+		return report.ImportReport, nil
+	}
+
+	err = s.ItemRepository.CreateItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = len(items)
+	logger.FromContext(ctx).Info("items imported", "imported", report.Imported, "failed", report.Failed, "mode", opts.Mode)
+
+	return report.ImportReport, nil
+}
+
+func (s *ImportServiceImpl) ExportMerchants(ctx context.Context, fn func(merchant *merchant_entity.Merchant) error) error {
+	ctx, span := tracing.Start(ctx, "ImportService.ExportMerchants")
+	defer span.End()
+
+	return s.MerchantRepository.ExportMerchants(ctx, fn)
+}
+
+func (s *ImportServiceImpl) ExportItems(ctx context.Context, merchantId string, fn func(item *item_entity.Item) error) error {
+	ctx, span := tracing.Start(ctx, "ImportService.ExportItems")
+	defer span.End()
+
+	if merchantId != "" {
+		isMerchantIdExists, err := s.MerchantRepository.VerifyId(ctx, merchantId)
+		if err != nil {
+			return err
+		}
+		if !isMerchantIdExists {
+			return merchant_exception.ErrMerchantIdNotFound
+		}
+	}
+
+	return s.ItemRepository.ExportItems(ctx, merchantId, fn)
+}
+
+type importReport struct {
+	*bulk_entity.ImportReport
+}
+
+func newImportReport(total int, opts *bulk_entity.ImportOptions) (*importReport, error) {
+	if opts.Mode != bulk_entity.ModeAtomic && opts.Mode != bulk_entity.ModeBestEffort {
+		return nil, bulk_exception.ErrInvalidMode
+	}
+
+	return &importReport{&bulk_entity.ImportReport{
+		Mode:    opts.Mode,
+		DryRun:  opts.DryRun,
+		Total:   total,
+		Created: []bulk_entity.RowResult{},
+		Errors:  []bulk_entity.RowError{},
+	}}, nil
+}
+
+// check records why a row is unusable and reports whether it is valid.
+func (r *importReport) check(line int, decodeErr error, payload any, language string) bool {
+	if decodeErr != nil {
+		r.Errors = append(r.Errors, bulk_entity.RowError{Line: line, Message: decodeErr.Error()})
+		r.Failed++
+		return false
+	}
+
+	if err := validator_helper.ValidatePayload(payload, language); err != nil {
+		r.fail(line, err)
+		return false
+	}
+
+	r.Valid++
+	return true
+}
+
+// reject fails a row that passed check.
+func (r *importReport) reject(line int, err error) {
+	r.Valid--
+	r.fail(line, err)
+}
+
+func (r *importReport) fail(line int, err error) {
+	appErr := exceptions.Translate(err)
+	r.Errors = append(r.Errors, bulk_entity.RowError{Line: line, Message: appErr.Message, Errors: appErr.Errors})
+	r.Failed++
+}
+
+// shouldImport tells whether the valid rows are written. Dry runs and atomic
+// imports with any failure write nothing and report no created ids.
+func (r *importReport) shouldImport(opts *bulk_entity.ImportOptions) bool {
+	if opts.DryRun || (opts.Mode == bulk_entity.ModeAtomic && r.Failed > 0) {
+		r.Created = []bulk_entity.RowResult{}
+		return false
+	}
+	return len(r.Created) > 0
+}
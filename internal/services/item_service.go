@@ -4,8 +4,12 @@ import (
 	"context"
 
 	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
 	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
+	pagination_helper "github.com/danzBraham/beli-mang/internal/helpers/pagination"
+	"github.com/danzBraham/beli-mang/internal/logger"
 	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
 	"github.com/oklog/ulid/v2"
 )
 
@@ -27,6 +31,9 @@ func NewItemService(itemRepostiory repositories.ItemRepository, merchantReposito
 }
 
 func (s *ItemServiceImpl) CreateItem(ctx context.Context, merchantId string, payload *item_entity.AddItemRequest) (*item_entity.AddItemResponse, error) {
+	ctx, span := tracing.Start(ctx, "ItemService.CreateItem")
+	defer span.End()
+
 	isMerchantIdExists, err := s.MerchantRepository.VerifyId(ctx, merchantId)
 	if err != nil {
 		return nil, err
@@ -48,6 +55,7 @@ func (s *ItemServiceImpl) CreateItem(ctx context.Context, merchantId string, pay
 	if err != nil {
 		return nil, err
 	}
+	logger.FromContext(ctx).Info("item created", "item_id", item.Id, "merchant_id", merchantId)
 
 	return &item_entity.AddItemResponse{
 		Id: item.Id,
@@ -55,6 +63,9 @@ func (s *ItemServiceImpl) CreateItem(ctx context.Context, merchantId string, pay
 }
 
 func (s *ItemServiceImpl) GetItems(ctx context.Context, merchantId string, params *item_entity.ItemQueryParams) (*item_entity.GetItemResponse, error) {
+	ctx, span := tracing.Start(ctx, "ItemService.GetItems")
+	defer span.End()
+
 	isMerchantIdExists, err := s.MerchantRepository.VerifyId(ctx, merchantId)
 	if err != nil {
 		return nil, err
@@ -63,10 +74,13 @@ func (s *ItemServiceImpl) GetItems(ctx context.Context, merchantId string, param
 		return nil, merchant_exception.ErrMerchantIdNotFound
 	}
 
-	items, err := s.ItemRepository.GetItems(ctx, params)
+	items, err := s.ItemRepository.GetItems(ctx, merchantId, params)
 	if err != nil {
 		return nil, err
 	}
+	items, nextCursor, prevCursor := pagination_helper.Paginate(items, params.Page, func(item *item_entity.Item) pagination_entity.Cursor {
+		return item.Position
+	})
 
 	getItems := []*item_entity.GetItem{}
 	for _, item := range items {
@@ -80,7 +94,7 @@ func (s *ItemServiceImpl) GetItems(ctx context.Context, merchantId string, param
 		})
 	}
 
-	totalItems, err := s.ItemRepository.CountItems(ctx)
+	totalItems, err := s.ItemRepository.CountItems(ctx, merchantId, params)
 	if err != nil {
 		return nil, err
 	}
@@ -88,9 +102,11 @@ func (s *ItemServiceImpl) GetItems(ctx context.Context, merchantId string, param
 	return &item_entity.GetItemResponse{
 		Data: getItems,
 		Meta: item_entity.Meta{
-			Limit:  params.Limit,
-			Offset: params.Offset,
-			Total:  totalItems,
+			Limit:      params.Page.Limit,
+			Offset:     params.Page.Offset,
+			Total:      totalItems,
+			NextCursor: nextCursor,
+			PrevCursor: prevCursor,
 		},
 	}, nil
 }
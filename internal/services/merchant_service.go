@@ -2,39 +2,62 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	geocoding_entity "github.com/danzBraham/beli-mang/internal/entities/geocoding"
 	merchant_entity "github.com/danzBraham/beli-mang/internal/entities/merchant"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
+	geo_exception "github.com/danzBraham/beli-mang/internal/exceptions/geo"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
+	pagination_helper "github.com/danzBraham/beli-mang/internal/helpers/pagination"
+	"github.com/danzBraham/beli-mang/internal/logger"
 	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
 	"github.com/oklog/ulid/v2"
 )
 
 type MerchantService interface {
 	CreateMerchant(ctx context.Context, userId string, payload *merchant_entity.AddMerchantRequest) (*merchant_entity.AddMerchantResponse, error)
 	GetMerchants(ctx context.Context, params *merchant_entity.MerchantQueryParams) (*merchant_entity.GetMerchantResponse, error)
+	SetPreparationTime(ctx context.Context, merchantId string, minutes *int) error
+	GetMerchantsWithin(ctx context.Context, params *merchant_entity.MerchantWithinQueryParams) (*merchant_entity.GetMerchantsWithinResponse, error)
 }
 
 type MerchantServiceImpl struct {
 	Repository repositories.MerchantRepository
+	Geocoder   geocoding.Geocoder
 }
 
-func NewMerchantService(repostiory repositories.MerchantRepository) MerchantService {
-	return &MerchantServiceImpl{Repository: repostiory}
+func NewMerchantService(repostiory repositories.MerchantRepository, geocoder geocoding.Geocoder) MerchantService {
+	return &MerchantServiceImpl{Repository: repostiory, Geocoder: geocoder}
 }
 
 func (s *MerchantServiceImpl) CreateMerchant(ctx context.Context, userId string, payload *merchant_entity.AddMerchantRequest) (*merchant_entity.AddMerchantResponse, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.CreateMerchant")
+	defer span.End()
+
+	location, address, err := placeMerchant(ctx, s.Geocoder, payload)
+	if err != nil {
+		return nil, err
+	}
+
 	merchant := &merchant_entity.Merchant{
-		Id:       ulid.Make().String(),
-		Name:     payload.Name,
-		Category: payload.Category,
-		ImageURL: payload.ImageURL,
-		Location: payload.Location,
-		UserId:   userId,
+		Id:                 ulid.Make().String(),
+		Name:               payload.Name,
+		Category:           payload.Category,
+		ImageURL:           payload.ImageURL,
+		Location:           location,
+		UserId:             userId,
+		Address:            address,
+		PreparationMinutes: payload.PreparationMinutes,
 	}
 
-	err := s.Repository.CreateMerchant(ctx, merchant)
+	err = s.Repository.CreateMerchant(ctx, merchant)
 	if err != nil {
 		return nil, err
 	}
+	logger.FromContext(ctx).Info("merchant created", "merchant_id", merchant.Id)
 
 	return &merchant_entity.AddMerchantResponse{
 		Id: merchant.Id,
@@ -42,10 +65,16 @@ func (s *MerchantServiceImpl) CreateMerchant(ctx context.Context, userId string,
 }
 
 func (s *MerchantServiceImpl) GetMerchants(ctx context.Context, params *merchant_entity.MerchantQueryParams) (*merchant_entity.GetMerchantResponse, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.GetMerchants")
+	defer span.End()
+
 	merchants, err := s.Repository.GetMerchants(ctx, params)
 	if err != nil {
 		return nil, err
 	}
+	merchants, nextCursor, prevCursor := pagination_helper.Paginate(merchants, params.Page, func(merchant *merchant_entity.Merchant) pagination_entity.Cursor {
+		return merchant.Position
+	})
 
 	getMerchants := []*merchant_entity.GetMerchant{}
 	for _, merchant := range merchants {
@@ -58,11 +87,12 @@ func (s *MerchantServiceImpl) GetMerchants(ctx context.Context, params *merchant
 				Lat:  merchant.Location.Lat,
 				Long: merchant.Location.Long,
 			},
+			Address:   merchant.Address,
 			CreatedAt: merchant.CreatedAt,
 		})
 	}
 
-	countMerchants, err := s.Repository.CountMerhcants(ctx)
+	countMerchants, err := s.Repository.CountMerchants(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -70,9 +100,102 @@ func (s *MerchantServiceImpl) GetMerchants(ctx context.Context, params *merchant
 	return &merchant_entity.GetMerchantResponse{
 		Data: getMerchants,
 		Meta: merchant_entity.Meta{
-			Limit:  params.Limit,
-			Offset: params.Offset,
-			Total:  countMerchants,
+			Limit:      params.Page.Limit,
+			Offset:     params.Page.Offset,
+			Total:      countMerchants,
+			NextCursor: nextCursor,
+			PrevCursor: prevCursor,
 		},
 	}, nil
 }
+
+func (s *MerchantServiceImpl) SetPreparationTime(ctx context.Context, merchantId string, minutes *int) error {
+	ctx, span := tracing.Start(ctx, "MerchantService.SetPreparationTime")
+	defer span.End()
+
+	err := s.Repository.UpdatePreparationMinutes(ctx, merchantId, minutes)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("merchant preparation time set", "merchant_id", merchantId, "minutes", minutes)
+	return nil
+}
+
+func (s *MerchantServiceImpl) GetMerchantsWithin(ctx context.Context, params *merchant_entity.MerchantWithinQueryParams) (*merchant_entity.GetMerchantsWithinResponse, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.GetMerchantsWithin")
+	defer span.End()
+
+	// one extra row tells a full viewport from a truncated one
+	clusters, err := s.Repository.GetMerchantsWithin(ctx, params, params.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	truncated := len(clusters) > params.Limit
+	if truncated {
+		clusters = clusters[:params.Limit]
+	}
+
+	data := make([]*merchant_entity.MerchantWithin, 0, len(clusters))
+	for _, cluster := range clusters {
+		within := &merchant_entity.MerchantWithin{
+			Type:     merchant_entity.TypeCluster,
+			Location: cluster.Location,
+			Count:    cluster.Count,
+		}
+		if cluster.Merchant != nil {
+			within.Type = merchant_entity.TypeMerchant
+			within.Merchant = &merchant_entity.GetMerchant{
+				Id:        cluster.Merchant.Id,
+				Name:      cluster.Merchant.Name,
+				Category:  cluster.Merchant.Category,
+				ImageURL:  cluster.Merchant.ImageURL,
+				Location:  cluster.Merchant.Location,
+				CreatedAt: cluster.Merchant.CreatedAt,
+			}
+		}
+		data = append(data, within)
+	}
+
+	return &merchant_entity.GetMerchantsWithinResponse{
+		Data: data,
+		Meta: merchant_entity.WithinMeta{
+			Count:     len(data),
+			Clustered: params.Zoom != nil && *params.Zoom < merchant_entity.MaxClusterZoom,
+			Truncated: truncated,
+		},
+	}, nil
+}
+
+// placeMerchant returns where a new merchant is and its address. The address
+// is geocoded when no location was sent, and the geocoder's formatted
+// address is kept.
+func placeMerchant(ctx context.Context, geocoder geocoding.Geocoder, payload *merchant_entity.AddMerchantRequest) (merchant_entity.Location, *string, error) {
+	var address *string
+	if payload.Address != "" {
+		address = &payload.Address
+	}
+	if payload.Location != nil {
+		return *payload.Location, address, nil
+	}
+
+	place, err := geocodeAddress(ctx, geocoder, payload.Address)
+	if err != nil {
+		return merchant_entity.Location{}, nil, err
+	}
+
+	return merchant_entity.Location{Lat: place.Location.Lat, Long: place.Location.Long}, &place.Address, nil
+}
+
+// geocodeAddress looks the address up, turning geocoder failures into the
+// errors a client gets back.
+func geocodeAddress(ctx context.Context, geocoder geocoding.Geocoder, address string) (*geocoding_entity.Place, error) {
+	place, err := geocoder.Geocode(ctx, address)
+	switch {
+	case errors.Is(err, geocoding.ErrNotFound):
+		return nil, geo_exception.ErrAddressNotFound
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", geo_exception.ErrGeocoderUnavailable, err)
+	}
+	return place, nil
+}
@@ -2,13 +2,28 @@ package services
 
 import (
 	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
 
+	"github.com/danzBraham/beli-mang/internal/config"
+	address_entity "github.com/danzBraham/beli-mang/internal/entities/address"
+	eta_entity "github.com/danzBraham/beli-mang/internal/entities/eta"
 	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	pagination_entity "github.com/danzBraham/beli-mang/internal/entities/pagination"
 	purchase_entity "github.com/danzBraham/beli-mang/internal/entities/purchase"
 	item_exception "github.com/danzBraham/beli-mang/internal/exceptions/item"
 	merchant_exception "github.com/danzBraham/beli-mang/internal/exceptions/merchant"
 	purchase_exception "github.com/danzBraham/beli-mang/internal/exceptions/purchase"
+	"github.com/danzBraham/beli-mang/internal/geocoding"
+	formula_helper "github.com/danzBraham/beli-mang/internal/helpers/formula"
+	pagination_helper "github.com/danzBraham/beli-mang/internal/helpers/pagination"
+	"github.com/danzBraham/beli-mang/internal/logger"
+	"github.com/danzBraham/beli-mang/internal/metrics"
 	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/routing"
+	"github.com/danzBraham/beli-mang/internal/tracing"
 	"github.com/oklog/ulid/v2"
 )
 
@@ -16,42 +31,92 @@ type PurchaseService interface {
 	GetMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) (*purchase_entity.GetMerchantsNearbyResponse, error)
 	EstimateOrder(ctx context.Context, userId string, payload *purchase_entity.UserEstimateRequest) (*purchase_entity.UserEstimateResponse, error)
 	CreateOrder(ctx context.Context, userId string, payload *purchase_entity.UserOrderRequest) (*purchase_entity.UserOrderResponse, error)
-	GetUserOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) ([]*purchase_entity.GetUserOrder, error)
+	GetUserOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) (*purchase_entity.GetUserOrdersResponse, error)
+	DeliverOrder(ctx context.Context, orderId, userId string, deliveredAt time.Time) (*purchase_entity.OrderDeliveredResponse, error)
+	GetEstimate(ctx context.Context, estimateId string) (*purchase_entity.GetEstimate, error)
+	RepriceEstimate(ctx context.Context, estimateId string) (*purchase_entity.GetEstimate, error)
+	VoidEstimate(ctx context.Context, estimateId string) (*purchase_entity.GetEstimate, error)
 }
 
 type PurchaseServiceImpl struct {
 	PurchaseRepository repositories.PurchaseRepository
 	MerchantRepository repositories.MerchantRepository
 	ItemRepository     repositories.ItemRepository
+	AddressRepository  repositories.AddressRepository
+	Router             routing.Router
+	StraightLine       routing.Router
+	Geocoder           geocoding.Geocoder
+	EtaService         EtaService
+	Config             config.PurchaseConfig
 }
 
 func NewPurchaseService(
 	purchaseRepository repositories.PurchaseRepository,
 	merchantRepository repositories.MerchantRepository,
 	itemRepository repositories.ItemRepository,
+	addressRepository repositories.AddressRepository,
+	router routing.Router,
+	straightLine routing.Router,
+	geocoder geocoding.Geocoder,
+	etaService EtaService,
+	cfg config.PurchaseConfig,
 ) PurchaseService {
 	return &PurchaseServiceImpl{
 		PurchaseRepository: purchaseRepository,
 		MerchantRepository: merchantRepository,
 		ItemRepository:     itemRepository,
+		AddressRepository:  addressRepository,
+		Router:             router,
+		StraightLine:       straightLine,
+		Geocoder:           geocoder,
+		EtaService:         etaService,
+		Config:             cfg,
 	}
 }
 
 func (s *PurchaseServiceImpl) GetMerchantsNearby(ctx context.Context, location *purchase_entity.Location, params *purchase_entity.MerchantNearbyQueryParams) (*purchase_entity.GetMerchantsNearbyResponse, error) {
+	ctx, span := tracing.Start(ctx, "PurchaseService.GetMerchantsNearby")
+	defer span.End()
+
 	merchantsNearby, err := s.PurchaseRepository.GetMerchantsNearby(ctx, location, params)
 	if err != nil {
 		return nil, err
 	}
+	merchantsNearby, nextCursor, prevCursor := pagination_helper.Paginate(merchantsNearby, params.Page, func(nearby *purchase_entity.MerchantNearby) pagination_entity.Cursor {
+		return nearby.Position
+	})
 
-	getMerchants := []*purchase_entity.GetMerchantsNearby{}
-	for _, merchant := range merchantsNearby {
-		items, err := s.ItemRepository.GetItemsByMerchantId(ctx, merchant.Id)
+	merchantIds := make([]string, 0, len(merchantsNearby))
+	for _, nearby := range merchantsNearby {
+		merchantIds = append(merchantIds, nearby.Merchant.Id)
+	}
+
+	// the repository reads every item when the limit is 0, here it means none
+	itemsByMerchant := map[string][]*item_entity.Item{}
+	if params.Items.Limit > 0 {
+		itemsByMerchant, err = s.ItemRepository.GetItemsByMerchantIds(ctx, merchantIds, &params.Items)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	routes, err := s.routeNearby(ctx, merchantsNearby, *location)
+	if err != nil {
+		return nil, err
+	}
+
+	// delivery times are estimated the way /users/estimate does for an order
+	// from the merchant alone
+	now := time.Now()
+	getMerchants := []*purchase_entity.GetMerchantsNearby{}
+	for i, nearby := range merchantsNearby {
+		eta := s.EtaService.Estimate(ctx, now, &eta_entity.Trip{
+			Travel:             routes[i].Duration,
+			PreparationMinutes: []*int{nearby.PreparationMinutes},
+		})
 
 		getItems := []*item_entity.GetItem{}
-		for _, item := range items {
+		for _, item := range itemsByMerchant[nearby.Merchant.Id] {
 			getItems = append(getItems, &item_entity.GetItem{
 				Id:        item.Id,
 				Name:      item.Name,
@@ -63,12 +128,14 @@ func (s *PurchaseServiceImpl) GetMerchantsNearby(ctx context.Context, location *
 		}
 
 		getMerchants = append(getMerchants, &purchase_entity.GetMerchantsNearby{
-			Merchant: merchant,
-			Items:    getItems,
+			Merchant:     nearby.Merchant,
+			Items:        getItems,
+			DistanceKm:   math.Round(nearby.DistanceKm*100) / 100,
+			DeliveryTime: eta.Minutes,
 		})
 	}
 
-	countMerchants, err := s.MerchantRepository.CountMerhcants(ctx)
+	countMerchants, err := s.PurchaseRepository.CountMerchantsNearby(ctx, location, params)
 	if err != nil {
 		return nil, err
 	}
@@ -76,29 +143,100 @@ func (s *PurchaseServiceImpl) GetMerchantsNearby(ctx context.Context, location *
 	return &purchase_entity.GetMerchantsNearbyResponse{
 		Data: getMerchants,
 		Meta: &purchase_entity.Meta{
-			Limit:  params.Limit,
-			Offset: params.Offset,
-			Total:  countMerchants,
+			Limit:      params.Page.Limit,
+			Offset:     params.Page.Offset,
+			Total:      countMerchants,
+			NextCursor: nextCursor,
+			PrevCursor: prevCursor,
 		},
 	}, nil
 }
 
+// routeNearby routes every merchant of a nearby page to location. Only the
+// merchants within NearbyRouteMaxKm are routed on the road network, a few at a
+// time and within NearbyRouteBudget for the whole page; the others, and those
+// the budget doesn't reach, travel in a straight line.
+func (s *PurchaseServiceImpl) routeNearby(ctx context.Context, merchantsNearby []*purchase_entity.MerchantNearby, location purchase_entity.Location) ([]routing.Route, error) {
+	routeCtx, cancel := context.WithTimeout(ctx, s.Config.NearbyRouteBudget)
+	defer cancel()
+
+	routes := make([]routing.Route, len(merchantsNearby))
+	errs := make([]error, len(merchantsNearby))
+	slots := make(chan struct{}, s.Config.NearbyRouteConcurrency)
+
+	var wg sync.WaitGroup
+	for i, nearby := range merchantsNearby {
+		from := purchase_entity.Location{Lat: nearby.Merchant.Location.Lat, Long: nearby.Merchant.Location.Long}
+		if nearby.DistanceKm > s.Config.NearbyRouteMaxKm {
+			routes[i], errs[i] = s.StraightLine.Route(ctx, from, location)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, from purchase_entity.Location) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			route, err := s.Router.Route(routeCtx, from, location)
+			if err != nil && ctx.Err() == nil {
+				route, err = s.StraightLine.Route(ctx, from, location)
+			}
+			routes[i], errs[i] = route, err
+		}(i, from)
+	}
+	wg.Wait()
+
+	if routeCtx.Err() != nil && ctx.Err() == nil {
+		logger.FromContext(ctx).Warn("nearby routing ran out of time", "budget", s.Config.NearbyRouteBudget, "merchants", len(merchantsNearby))
+	}
+	return routes, errors.Join(errs...)
+}
+
 func (s *PurchaseServiceImpl) EstimateOrder(ctx context.Context, userId string, payload *purchase_entity.UserEstimateRequest) (*purchase_entity.UserEstimateResponse, error) {
-	estimateOrder := &purchase_entity.EstimateOrder{
-		Id:           ulid.Make().String(),
-		UserLocation: payload.UserLocation,
+	ctx, span := tracing.Start(ctx, "PurchaseService.EstimateOrder")
+	defer span.End()
+
+	merchantIds := []string{}
+	itemIds := []string{}
+	for _, order := range payload.Orders {
+		merchantIds = append(merchantIds, order.MerchantId)
+		for _, item := range order.Items {
+			itemIds = append(itemIds, item.Id)
+		}
+	}
+
+	pickups, err := s.MerchantRepository.GetPickupsByIds(ctx, merchantIds)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.ItemRepository.GetItemsByIds(ctx, itemIds)
+	if err != nil {
+		return nil, err
+	}
+
+	estimateOrder := &purchase_entity.EstimateOrder{Id: ulid.Make().String()}
+	if err := s.setDestination(ctx, userId, payload, estimateOrder); err != nil {
+		return nil, err
 	}
 	orderMerchants := []*purchase_entity.OrderMerchant{}
 	orderItems := []*purchase_entity.OrderItem{}
 
+	// the user and every merchant must fit in a circle of MaxDistanceKm, the
+	// slowest route from a merchant to the user decides the travel time
+	points := []purchase_entity.Location{estimateOrder.UserLocation}
+	var slowest routing.Route
+	trip := &eta_entity.Trip{}
+
 	for _, order := range payload.Orders {
-		isMerchantIdExists, err := s.MerchantRepository.VerifyId(ctx, order.MerchantId)
-		if err != nil {
-			return nil, err
-		}
-		if !isMerchantIdExists {
+		pickup, ok := pickups[order.MerchantId]
+		if !ok {
 			return nil, merchant_exception.ErrMerchantIdNotFound
 		}
+		location := purchase_entity.Location{Lat: pickup.Location.Lat, Long: pickup.Location.Long}
+		points = append(points, location)
+		trip.PreparationMinutes = append(trip.PreparationMinutes, pickup.PreparationMinutes)
 
 		orderMerchant := &purchase_entity.OrderMerchant{
 			Id:              ulid.Make().String(),
@@ -107,68 +245,281 @@ func (s *PurchaseServiceImpl) EstimateOrder(ctx context.Context, userId string,
 			EstimateId:      estimateOrder.Id,
 		}
 
-		orderMerchants = append(orderMerchants, orderMerchant)
-
-		for _, item := range order.Items {
-			isItemIdExists, err := s.ItemRepository.VerifyId(ctx, item.Id)
-			if err != nil {
-				return nil, err
-			}
-			if !isItemIdExists {
+		for _, orderedItem := range order.Items {
+			item, ok := items[orderedItem.Id]
+			if !ok {
 				return nil, item_exception.ErrItemIdNotFound
 			}
+			if item.MerchantId != order.MerchantId {
+				return nil, item_exception.ErrItemNotInMerchant
+			}
 
-			orderItems = append(orderItems, &purchase_entity.OrderItem{
+			orderItem := &purchase_entity.OrderItem{
 				Id:              ulid.Make().String(),
 				ItemId:          item.Id,
-				Quantity:        item.Quantity,
+				Quantity:        orderedItem.Quantity,
+				TotalItemPrice:  orderedItem.Quantity * item.Price,
 				OrderMerchantId: orderMerchant.Id,
-			})
+			}
+			orderMerchant.TotalMerchantPrice += orderItem.TotalItemPrice
+			orderItems = append(orderItems, orderItem)
+		}
+
+		estimateOrder.TotalPrice += orderMerchant.TotalMerchantPrice
+		orderMerchants = append(orderMerchants, orderMerchant)
+	}
+
+	circle := formula_helper.SmallestEnclosingCircle(points)
+	logger.FromContext(ctx).Debug("enclosing circle computed",
+		"estimate_id", estimateOrder.Id,
+		"radius_km", circle.Radius,
+		"max_distance_km", s.Config.MaxDistanceKm,
+	)
+	if circle.Radius > s.Config.MaxDistanceKm {
+		metrics.EstimatesRejected.WithLabelValues("distance_too_far").Inc()
+		logger.FromContext(ctx).Info("estimate rejected", "reason", "distance_too_far", "merchants", len(orderMerchants))
+		return nil, purchase_exception.ErrDistanceTooFar
+	}
+
+	for _, location := range points[1:] {
+		route, err := s.Router.Route(ctx, location, estimateOrder.UserLocation)
+		if err != nil {
+			return nil, err
+		}
+		if route.Duration > slowest.Duration {
+			slowest = route
+		}
+	}
+	trip.Travel = slowest.Duration
+
+	eta := s.EtaService.Estimate(ctx, time.Now(), trip)
+	estimateOrder.EstimatedDeliveryTime = eta.Minutes
+	estimateOrder.RouteDistanceKm = slowest.DistanceKm
+	estimateOrder.RouteDurationSeconds = int(slowest.Duration.Round(time.Second) / time.Second)
+	estimateOrder.PreparationMinutes = eta.PreparationMinutes
+
+	// the address is a convenience for couriers, an estimate never fails on it
+	if estimateOrder.UserAddress == nil {
+		place, err := s.Geocoder.Reverse(ctx, estimateOrder.UserLocation)
+		if err == nil {
+			estimateOrder.UserAddress = &place.Address
+		} else if !errors.Is(err, geocoding.ErrNotFound) && !errors.Is(err, geocoding.ErrDisabled) {
+			logger.FromContext(ctx).Warn("estimate location not reverse geocoded", "estimate_id", estimateOrder.Id, "error", err)
 		}
 	}
 
-	estimateOrder, err := s.PurchaseRepository.CreateEstimateOrder(ctx, estimateOrder, orderMerchants, orderItems)
+	err = s.PurchaseRepository.CreateEstimateOrder(ctx, estimateOrder, orderMerchants, orderItems)
 	if err != nil {
 		return nil, err
 	}
+	metrics.EstimatesCreated.Inc()
+	logger.FromContext(ctx).Info("estimate created",
+		"estimate_id", estimateOrder.Id,
+		"total_price", estimateOrder.TotalPrice,
+		"delivery_time_minutes", estimateOrder.EstimatedDeliveryTime,
+		"route_distance_km", slowest.DistanceKm,
+	)
 
 	return &purchase_entity.UserEstimateResponse{
-		TotalPrice:      estimateOrder.TotalPrice,
-		DeliveryTime:    estimateOrder.EstimatedDeliveryTime,
+		TotalPrice:   estimateOrder.TotalPrice,
+		DeliveryTime: estimateOrder.EstimatedDeliveryTime,
+		DeliveryTimeRange: purchase_entity.DeliveryTimeRange{
+			Min: eta.MinMinutes,
+			Max: eta.MaxMinutes,
+		},
+		UserAddress:     estimateOrder.UserAddress,
 		EstimateOrderId: estimateOrder.Id,
 	}, nil
 }
 
-func (s *PurchaseServiceImpl) CreateOrder(ctx context.Context, userId string, payload *purchase_entity.UserOrderRequest) (*purchase_entity.UserOrderResponse, error) {
-	isEstimateIdExists, err := s.PurchaseRepository.VerifyEstimateId(ctx, payload.EstimateId)
+// setDestination decides where the estimate delivers to: the location in the
+// request, else the saved address it names, else the user's default address.
+// A saved address is copied onto the estimate so later edits don't change it.
+func (s *PurchaseServiceImpl) setDestination(ctx context.Context, userId string, payload *purchase_entity.UserEstimateRequest, estimateOrder *purchase_entity.EstimateOrder) error {
+	if payload.UserLocation != nil {
+		estimateOrder.UserLocation = *payload.UserLocation
+		return nil
+	}
+
+	var address *address_entity.Address
+	var err error
+	if payload.AddressId != "" {
+		address, err = s.AddressRepository.GetAddressById(ctx, userId, payload.AddressId)
+	} else {
+		address, err = s.AddressRepository.GetDefaultAddress(ctx, userId)
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if !isEstimateIdExists {
-		return nil, purchase_exception.ErrEstimateIdNotFound
+	if address == nil {
+		return purchase_exception.ErrMissingLocation
 	}
 
+	estimateOrder.UserLocation = address.Location
+	estimateOrder.UserAddress = address.Street
+	estimateOrder.AddressId = &address.Id
+	estimateOrder.AddressLabel = &address.Label
+	estimateOrder.CourierNotes = address.Notes
+	return nil
+}
+
+func (s *PurchaseServiceImpl) CreateOrder(ctx context.Context, userId string, payload *purchase_entity.UserOrderRequest) (*purchase_entity.UserOrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "PurchaseService.CreateOrder")
+	defer span.End()
+
 	userOrder := &purchase_entity.UserOrder{
 		Id:         ulid.Make().String(),
 		EstimateId: payload.EstimateId,
 		UserId:     userId,
 	}
 
-	err = s.PurchaseRepository.CreateOrder(ctx, userOrder)
+	err := s.PurchaseRepository.CreateOrder(ctx, userOrder)
 	if err != nil {
 		return nil, err
 	}
+	metrics.OrdersPlaced.Inc()
+	logger.FromContext(ctx).Info("order placed", "order_id", userOrder.Id, "estimate_id", userOrder.EstimateId)
 
 	return &purchase_entity.UserOrderResponse{
 		OrderId: userOrder.Id,
 	}, nil
 }
 
-func (s *PurchaseServiceImpl) GetUserOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) ([]*purchase_entity.GetUserOrder, error) {
-	getOrders, err := s.PurchaseRepository.GetOrders(ctx, userId, params)
+func (s *PurchaseServiceImpl) GetUserOrders(ctx context.Context, userId string, params *purchase_entity.OrderQueryParams) (*purchase_entity.GetUserOrdersResponse, error) {
+	ctx, span := tracing.Start(ctx, "PurchaseService.GetUserOrders")
+	defer span.End()
+
+	history, err := s.PurchaseRepository.GetOrders(ctx, userId, params)
+	if err != nil {
+		return nil, err
+	}
+	history, nextCursor, prevCursor := pagination_helper.Paginate(history, params.Page, func(order *purchase_entity.UserOrderHistory) pagination_entity.Cursor {
+		return order.Position
+	})
+
+	getOrders := make([]*purchase_entity.GetUserOrder, 0, len(history))
+	for _, order := range history {
+		getOrders = append(getOrders, order.Order)
+	}
+
+	return &purchase_entity.GetUserOrdersResponse{
+		Data: getOrders,
+		Meta: purchase_entity.OrderMeta{
+			Limit:      params.Page.Limit,
+			NextCursor: nextCursor,
+			PrevCursor: prevCursor,
+		},
+	}, nil
+}
+
+// DeliverOrder records the delivery the ETA calibration learns from. An empty
+// userId delivers any user's order.
+func (s *PurchaseServiceImpl) DeliverOrder(ctx context.Context, orderId, userId string, deliveredAt time.Time) (*purchase_entity.OrderDeliveredResponse, error) {
+	ctx, span := tracing.Start(ctx, "PurchaseService.DeliverOrder")
+	defer span.End()
+
+	if deliveredAt.After(time.Now()) {
+		return nil, purchase_exception.ErrInvalidDeliveredAt
+	}
+
+	err := s.PurchaseRepository.MarkOrderDelivered(ctx, orderId, userId, deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	metrics.OrdersDelivered.Inc()
+	logger.FromContext(ctx).Info("order delivered", "order_id", orderId)
+
+	return &purchase_entity.OrderDeliveredResponse{
+		OrderId:     orderId,
+		DeliveredAt: deliveredAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (s *PurchaseServiceImpl) GetEstimate(ctx context.Context, estimateId string) (*purchase_entity.GetEstimate, error) {
+	ctx, span := tracing.Start(ctx, "PurchaseService.GetEstimate")
+	defer span.End()
+
+	estimate, err := s.PurchaseRepository.GetEstimateState(ctx, estimateId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGetEstimate(estimate), nil
+}
+
+func (s *PurchaseServiceImpl) RepriceEstimate(ctx context.Context, estimateId string) (*purchase_entity.GetEstimate, error) {
+	ctx, span := tracing.Start(ctx, "PurchaseService.RepriceEstimate")
+	defer span.End()
+
+	estimate, err := s.getModifiableEstimate(ctx, estimateId)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPrice, err := s.PurchaseRepository.RepriceEstimate(ctx, estimateId)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("estimate repriced",
+		"estimate_id", estimateId,
+		"previous_total_price", estimate.TotalPrice,
+		"total_price", totalPrice,
+	)
+
+	getEstimate := toGetEstimate(estimate)
+	getEstimate.PreviousTotalPrice = &estimate.TotalPrice
+	getEstimate.TotalPrice = totalPrice
+	return getEstimate, nil
+}
+
+func (s *PurchaseServiceImpl) VoidEstimate(ctx context.Context, estimateId string) (*purchase_entity.GetEstimate, error) {
+	ctx, span := tracing.Start(ctx, "PurchaseService.VoidEstimate")
+	defer span.End()
+
+	estimate, err := s.getModifiableEstimate(ctx, estimateId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.PurchaseRepository.VoidEstimate(ctx, estimateId)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("estimate voided", "estimate_id", estimateId)
+
+	estimate.IsVoided = true
+	return toGetEstimate(estimate), nil
+}
+
+// getModifiableEstimate returns the estimate if it is neither ordered nor voided.
+func (s *PurchaseServiceImpl) getModifiableEstimate(ctx context.Context, estimateId string) (*purchase_entity.EstimateState, error) {
+	estimate, err := s.PurchaseRepository.GetEstimateState(ctx, estimateId)
 	if err != nil {
 		return nil, err
 	}
+	if estimate.IsVoided {
+		return nil, purchase_exception.ErrEstimateVoided
+	}
+	if estimate.IsOrdered {
+		return nil, purchase_exception.ErrEstimateOrdered
+	}
+	return estimate, nil
+}
+
+func toGetEstimate(estimate *purchase_entity.EstimateState) *purchase_entity.GetEstimate {
+	status := purchase_entity.EstimateOpen
+	switch {
+	case estimate.IsVoided:
+		status = purchase_entity.EstimateVoided
+	case estimate.IsOrdered:
+		status = purchase_entity.EstimateOrdered
+	}
 
-	return getOrders, nil
+	return &purchase_entity.GetEstimate{
+		Id:           estimate.Id,
+		TotalPrice:   estimate.TotalPrice,
+		DeliveryTime: estimate.EstimatedDeliveryTime,
+		Status:       status,
+		CreatedAt:    estimate.CreatedAt,
+	}
 }
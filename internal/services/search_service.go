@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	item_entity "github.com/danzBraham/beli-mang/internal/entities/item"
+	search_entity "github.com/danzBraham/beli-mang/internal/entities/search"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
+)
+
+type SearchService interface {
+	Search(ctx context.Context, params *search_entity.SearchQueryParams) (*search_entity.SearchResponse, error)
+}
+
+type SearchServiceImpl struct {
+	Repository repositories.SearchRepository
+}
+
+func NewSearchService(repository repositories.SearchRepository) SearchService {
+	return &SearchServiceImpl{Repository: repository}
+}
+
+func (s *SearchServiceImpl) Search(ctx context.Context, params *search_entity.SearchQueryParams) (*search_entity.SearchResponse, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.Search")
+	defer span.End()
+
+	matches, total, err := s.Repository.SearchMerchants(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantIds := make([]string, 0, len(matches))
+	for _, match := range matches {
+		merchantIds = append(merchantIds, match.Merchant.Id)
+	}
+
+	itemsByMerchant, err := s.Repository.SearchItems(ctx, params.Query, merchantIds, params.ItemLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*search_entity.SearchResult, 0, len(matches))
+	for _, match := range matches {
+		searchItems := []*search_entity.SearchItem{}
+		for _, itemMatch := range itemsByMerchant[match.Merchant.Id] {
+			searchItems = append(searchItems, &search_entity.SearchItem{
+				GetItem: item_entity.GetItem{
+					Id:        itemMatch.Item.Id,
+					Name:      itemMatch.Item.Name,
+					Category:  itemMatch.Item.Category,
+					Price:     itemMatch.Item.Price,
+					ImageURL:  itemMatch.Item.ImageURL,
+					CreatedAt: itemMatch.Item.CreatedAt,
+				},
+				Highlight: itemMatch.Highlight,
+			})
+		}
+
+		result := &search_entity.SearchResult{
+			Merchant: match.Merchant,
+			Items:    searchItems,
+			Score:    math.Round(match.Score*10000) / 10000,
+		}
+		if match.DistanceKm != nil {
+			distanceKm := math.Round(*match.DistanceKm*100) / 100
+			result.DistanceKm = &distanceKm
+		}
+		results = append(results, result)
+	}
+
+	return &search_entity.SearchResponse{
+		Data: results,
+		Meta: search_entity.Meta{
+			Limit:  params.Limit,
+			Offset: params.Offset,
+			Total:  total,
+		},
+	}, nil
+}
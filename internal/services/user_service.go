@@ -2,13 +2,17 @@ package services
 
 import (
 	"context"
-	"time"
+	"errors"
 
+	"github.com/danzBraham/beli-mang/internal/config"
 	user_entity "github.com/danzBraham/beli-mang/internal/entities/user"
+	auth_exception "github.com/danzBraham/beli-mang/internal/exceptions/auth"
 	user_exception "github.com/danzBraham/beli-mang/internal/exceptions/user"
 	bcrypt_helper "github.com/danzBraham/beli-mang/internal/helpers/bcrypt"
 	jwt_helper "github.com/danzBraham/beli-mang/internal/helpers/jwt"
+	"github.com/danzBraham/beli-mang/internal/logger"
 	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
 	"github.com/oklog/ulid/v2"
 )
 
@@ -17,17 +21,30 @@ type UserService interface {
 	LoginAdminUser(ctx context.Context, payload *user_entity.LoginUserRequest) (*user_entity.LoginUserResponse, error)
 	RegisterUser(ctx context.Context, payload *user_entity.RegisterUserRequest) (*user_entity.RegisterUserResponse, error)
 	LoginUser(ctx context.Context, payload *user_entity.LoginUserRequest) (*user_entity.LoginUserResponse, error)
+	GetUsers(ctx context.Context, params *user_entity.UserQueryParams) ([]*user_entity.GetUser, error)
+	GetUserByUsername(ctx context.Context, username string) (*user_entity.GetUser, error)
+	PromoteUser(ctx context.Context, username string) (*user_entity.GetUser, error)
+	ResetPassword(ctx context.Context, username string, payload *user_entity.ResetPasswordRequest) error
+	SetUserDisabled(ctx context.Context, username string, isDisabled bool) (*user_entity.GetUser, error)
+	VerifyActive(ctx context.Context, userId string) error
 }
 
 type UserServiceImpl struct {
 	Repository repositories.UserRepository
+	Config     config.AuthConfig
 }
 
-func NewUserService(repository repositories.UserRepository) UserService {
-	return &UserServiceImpl{Repository: repository}
+func NewUserService(repository repositories.UserRepository, cfg config.AuthConfig) UserService {
+	return &UserServiceImpl{
+		Repository: repository,
+		Config:     cfg,
+	}
 }
 
 func (s *UserServiceImpl) RegisterAdminUser(ctx context.Context, payload *user_entity.RegisterUserRequest) (*user_entity.RegisterUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.RegisterAdminUser")
+	defer span.End()
+
 	isUsernameExists, err := s.Repository.VerifyUsername(ctx, payload.Username)
 	if err != nil {
 		return nil, err
@@ -44,7 +61,7 @@ func (s *UserServiceImpl) RegisterAdminUser(ctx context.Context, payload *user_e
 		return nil, user_exception.ErrAdminEmailAlreadyExists
 	}
 
-	hashedPassword, err := bcrypt_helper.HashPassword(payload.Password)
+	hashedPassword, err := bcrypt_helper.HashPassword(payload.Password, s.Config.BcryptCost)
 	if err != nil {
 		return nil, err
 	}
@@ -61,8 +78,9 @@ func (s *UserServiceImpl) RegisterAdminUser(ctx context.Context, payload *user_e
 	if err != nil {
 		return nil, err
 	}
+	logger.FromContext(ctx).Info("user registered", "user_id", user.Id, "is_admin", user.IsAdmin)
 
-	token, err := jwt_helper.GenerateToken(2*time.Hour, user.Id, user.IsAdmin)
+	token, err := jwt_helper.GenerateToken(s.Config.JWTSecret, s.Config.TokenTTL, user.Id, user.IsAdmin)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +91,9 @@ func (s *UserServiceImpl) RegisterAdminUser(ctx context.Context, payload *user_e
 }
 
 func (s *UserServiceImpl) LoginAdminUser(ctx context.Context, payload *user_entity.LoginUserRequest) (*user_entity.LoginUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.LoginAdminUser")
+	defer span.End()
+
 	user, err := s.Repository.GetAdminUserByUsername(ctx, payload.Username)
 	if err != nil {
 		return nil, err
@@ -80,10 +101,16 @@ func (s *UserServiceImpl) LoginAdminUser(ctx context.Context, payload *user_enti
 
 	err = bcrypt_helper.VerifyPassword(user.Password, payload.Password)
 	if err != nil {
+		logger.FromContext(ctx).Warn("login rejected", "user_id", user.Id, "reason", "invalid password")
 		return nil, user_exception.ErrInvalidPassword
 	}
 
-	token, err := jwt_helper.GenerateToken(2*time.Hour, user.Id, user.IsAdmin)
+	if user.IsDisabled {
+		logger.FromContext(ctx).Warn("login rejected", "user_id", user.Id, "reason", "user disabled")
+		return nil, user_exception.ErrUserDisabled
+	}
+
+	token, err := jwt_helper.GenerateToken(s.Config.JWTSecret, s.Config.TokenTTL, user.Id, user.IsAdmin)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +121,9 @@ func (s *UserServiceImpl) LoginAdminUser(ctx context.Context, payload *user_enti
 }
 
 func (s *UserServiceImpl) RegisterUser(ctx context.Context, payload *user_entity.RegisterUserRequest) (*user_entity.RegisterUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.RegisterUser")
+	defer span.End()
+
 	isUsernameExists, err := s.Repository.VerifyUsername(ctx, payload.Username)
 	if err != nil {
 		return nil, err
@@ -110,7 +140,7 @@ func (s *UserServiceImpl) RegisterUser(ctx context.Context, payload *user_entity
 		return nil, user_exception.ErrUserEmailAlreadyExists
 	}
 
-	hashedPassword, err := bcrypt_helper.HashPassword(payload.Password)
+	hashedPassword, err := bcrypt_helper.HashPassword(payload.Password, s.Config.BcryptCost)
 	if err != nil {
 		return nil, err
 	}
@@ -127,8 +157,9 @@ func (s *UserServiceImpl) RegisterUser(ctx context.Context, payload *user_entity
 	if err != nil {
 		return nil, err
 	}
+	logger.FromContext(ctx).Info("user registered", "user_id", user.Id, "is_admin", user.IsAdmin)
 
-	token, err := jwt_helper.GenerateToken(2*time.Hour, user.Id, user.IsAdmin)
+	token, err := jwt_helper.GenerateToken(s.Config.JWTSecret, s.Config.TokenTTL, user.Id, user.IsAdmin)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +170,9 @@ func (s *UserServiceImpl) RegisterUser(ctx context.Context, payload *user_entity
 }
 
 func (s *UserServiceImpl) LoginUser(ctx context.Context, payload *user_entity.LoginUserRequest) (*user_entity.LoginUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.LoginUser")
+	defer span.End()
+
 	user, err := s.Repository.GetUserByUsername(ctx, payload.Username)
 	if err != nil {
 		return nil, err
@@ -146,10 +180,16 @@ func (s *UserServiceImpl) LoginUser(ctx context.Context, payload *user_entity.Lo
 
 	err = bcrypt_helper.VerifyPassword(user.Password, payload.Password)
 	if err != nil {
+		logger.FromContext(ctx).Warn("login rejected", "user_id", user.Id, "reason", "invalid password")
 		return nil, user_exception.ErrInvalidPassword
 	}
 
-	token, err := jwt_helper.GenerateToken(2*time.Hour, user.Id, user.IsAdmin)
+	if user.IsDisabled {
+		logger.FromContext(ctx).Warn("login rejected", "user_id", user.Id, "reason", "user disabled")
+		return nil, user_exception.ErrUserDisabled
+	}
+
+	token, err := jwt_helper.GenerateToken(s.Config.JWTSecret, s.Config.TokenTTL, user.Id, user.IsAdmin)
 	if err != nil {
 		return nil, err
 	}
@@ -158,3 +198,135 @@ func (s *UserServiceImpl) LoginUser(ctx context.Context, payload *user_entity.Lo
 		Token: token,
 	}, nil
 }
+
+func (s *UserServiceImpl) GetUsers(ctx context.Context, params *user_entity.UserQueryParams) ([]*user_entity.GetUser, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsers")
+	defer span.End()
+
+	users, err := s.Repository.GetUsers(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	getUsers := []*user_entity.GetUser{}
+	for _, user := range users {
+		getUsers = append(getUsers, toGetUser(user))
+	}
+
+	return getUsers, nil
+}
+
+func (s *UserServiceImpl) GetUserByUsername(ctx context.Context, username string) (*user_entity.GetUser, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByUsername")
+	defer span.End()
+
+	user, err := s.Repository.FindUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGetUser(user), nil
+}
+
+func (s *UserServiceImpl) PromoteUser(ctx context.Context, username string) (*user_entity.GetUser, error) {
+	ctx, span := tracing.Start(ctx, "UserService.PromoteUser")
+	defer span.End()
+
+	user, err := s.Repository.FindUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin {
+		return toGetUser(user), nil
+	}
+
+	// admin and user emails are unique per role
+	isAdminEmailExists, err := s.Repository.VerifyAdminEmail(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if isAdminEmailExists {
+		return nil, user_exception.ErrAdminEmailAlreadyExists
+	}
+
+	err = s.Repository.UpdateIsAdmin(ctx, user.Id, true)
+	if err != nil {
+		return nil, err
+	}
+	user.IsAdmin = true
+	logger.FromContext(ctx).Info("user promoted to admin", "user_id", user.Id)
+
+	return toGetUser(user), nil
+}
+
+func (s *UserServiceImpl) ResetPassword(ctx context.Context, username string, payload *user_entity.ResetPasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	user, err := s.Repository.FindUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt_helper.HashPassword(payload.Password, s.Config.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	err = s.Repository.UpdatePassword(ctx, user.Id, hashedPassword)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("password reset", "user_id", user.Id)
+
+	return nil
+}
+
+func (s *UserServiceImpl) SetUserDisabled(ctx context.Context, username string, isDisabled bool) (*user_entity.GetUser, error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetUserDisabled")
+	defer span.End()
+
+	user, err := s.Repository.FindUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repository.UpdateIsDisabled(ctx, user.Id, isDisabled)
+	if err != nil {
+		return nil, err
+	}
+	user.IsDisabled = isDisabled
+	logger.FromContext(ctx).Info("user disabled state changed", "user_id", user.Id, "is_disabled", isDisabled)
+
+	return toGetUser(user), nil
+}
+
+// VerifyActive rejects tokens whose user has since been disabled or removed,
+// so disabling a user takes effect before their tokens expire.
+func (s *UserServiceImpl) VerifyActive(ctx context.Context, userId string) error {
+	ctx, span := tracing.Start(ctx, "UserService.VerifyActive")
+	defer span.End()
+
+	isActive, err := s.Repository.VerifyUserActive(ctx, userId)
+	if errors.Is(err, user_exception.ErrUserNotFound) {
+		return auth_exception.ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	if !isActive {
+		return user_exception.ErrUserDisabled
+	}
+	return nil
+}
+
+func toGetUser(user *user_entity.User) *user_entity.GetUser {
+	return &user_entity.GetUser{
+		Id:         user.Id,
+		Username:   user.Username,
+		Email:      user.Email,
+		IsAdmin:    user.IsAdmin,
+		IsDisabled: user.IsDisabled,
+		CreatedAt:  user.CreatedAt,
+	}
+}
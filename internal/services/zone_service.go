@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	geo_entity "github.com/danzBraham/beli-mang/internal/entities/geo"
+	zone_entity "github.com/danzBraham/beli-mang/internal/entities/zone"
+	zone_exception "github.com/danzBraham/beli-mang/internal/exceptions/zone"
+	"github.com/danzBraham/beli-mang/internal/repositories"
+	"github.com/danzBraham/beli-mang/internal/tracing"
+)
+
+type ZoneService interface {
+	GetZones(ctx context.Context, params *zone_entity.ZoneQueryParams) (*geo_entity.FeatureCollection, error)
+}
+
+type ZoneServiceImpl struct {
+	Repository repositories.ZoneRepository
+}
+
+func NewZoneService(repository repositories.ZoneRepository) ZoneService {
+	return &ZoneServiceImpl{Repository: repository}
+}
+
+func (s *ZoneServiceImpl) GetZones(ctx context.Context, params *zone_entity.ZoneQueryParams) (*geo_entity.FeatureCollection, error) {
+	ctx, span := tracing.Start(ctx, "ZoneService.GetZones")
+	defer span.End()
+
+	// one extra row tells a full response from a truncated one
+	zones, err := s.Repository.GetZones(ctx, params, zone_entity.MaxCells+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) > zone_entity.MaxCells {
+		return nil, zone_exception.ErrTooManyCells
+	}
+
+	features := make([]*geo_entity.Feature, 0, len(zones))
+	for _, zone := range zones {
+		features = append(features, &geo_entity.Feature{
+			Type:     geo_entity.TypeFeature,
+			Id:       zone.Geohash,
+			Geometry: zone.Geometry,
+			Properties: &zone_entity.ZoneProperties{
+				Geohash:                      zone.Geohash,
+				Merchants:                    zone.Merchants,
+				Orders:                       zone.Orders,
+				AverageBasket:                round2(zone.AverageBasket),
+				AverageDeliveryTime:          round2(zone.AverageDeliveryTime),
+				AverageEstimatedDeliveryTime: round2(zone.AverageEstimatedDeliveryTime),
+			},
+		})
+	}
+
+	return geo_entity.NewFeatureCollection(features), nil
+}
+
+// round2 rounds to two decimals, keeping nil for cells without orders.
+func round2(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	rounded := math.Round(*value*100) / 100
+	return &rounded
+}